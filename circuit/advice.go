@@ -0,0 +1,151 @@
+package circuit
+
+import (
+	"fmt"
+
+	"github.com/consensys/gkr-mimc/poly"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+)
+
+// Hint computes the values of advice layers from the values of other layers, as gnark hints do.
+// `outputs` is preallocated : `outputs[i]` receives the values of the advice layer whose `Output`
+// is `i`, and has as many instances as the input layers. `inputs` holds the values of the
+// layers listed in `Advice.In`.
+type Hint func(outputs [][]fr.Element, inputs ...[]fr.Element) error
+
+// Advice describes how the values of an advice layer are computed by `Circuit.Assign`.
+// Advice layers are input layers : the values are not checked by GKR, the relations
+// they satisfy must be enforced by the rest of the circuit. For instance, an inverse is
+// checked by an output layer computing `x * inv - 1` that the verifier expects to be zero.
+type Advice struct {
+	// Name of the hint in the hint registry, see `RegisterHint`
+	Hint string `json:"hint"`
+	// Layers whose values are passed to the hint, they can come after the advice layer
+	In []int `json:"in"`
+	// Index of the output of the hint held by the layer
+	Output int `json:"output"`
+}
+
+// IsAdviceLayer returns true if the layer is an input layer computed by a hint, see `Layer.Advice`
+func (c Circuit) IsAdviceLayer(layer int) bool {
+	return c[layer].Advice != nil
+}
+
+// ExternalInputArity returns the number of input layers that are not advice layers.
+// They are the inputs expected by `Assign`.
+func (c Circuit) ExternalInputArity() int {
+	count := 0
+	for l := 0; l < c.InputArity(); l++ {
+		if !c.IsAdviceLayer(l) {
+			count++
+		}
+	}
+	return count
+}
+
+// validateAdvice checks the advice of the input layer `l`, if any
+func (c Circuit) validateAdvice(l int) error {
+	if !c.IsAdviceLayer(l) {
+		return nil
+	}
+
+	if _, err := HintFromName(c[l].Advice.Hint); err != nil {
+		return layerErrorf(l, ErrInvalidAdvice, "%v", err)
+	}
+	if c[l].Advice.Output < 0 {
+		return layerErrorf(l, ErrInvalidAdvice, "negative hint output %v", c[l].Advice.Output)
+	}
+	for _, inp := range c[l].Advice.In {
+		if inp < 0 || inp >= len(c) {
+			return layerErrorf(l, ErrInputOutOfRange, "hint input %v, the circuit has %v layers", inp, len(c))
+		}
+	}
+	return nil
+}
+
+// dependencies returns the layers needed to compute the layer `l`
+func (c Circuit) dependencies(l int) []int {
+	if c.IsAdviceLayer(l) {
+		return c[l].Advice.In
+	}
+	return c[l].In
+}
+
+// evaluationOrder returns the layers in an order where each layer comes after all its dependencies.
+// Without advice layers, it is the order of the layers. It returns an error if the value of an
+// advice layer depends on itself.
+func (c Circuit) evaluationOrder() ([]int, error) {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	state := make([]int, len(c))
+	order := make([]int, 0, len(c))
+
+	var visit func(l int) error
+	visit = func(l int) error {
+		switch state[l] {
+		case visited:
+			return nil
+		case visiting:
+			return layerErrorf(l, ErrInvalidAdvice, "its value depends on itself through a hint")
+		}
+
+		state[l] = visiting
+		for _, dep := range c.dependencies(l) {
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		state[l] = visited
+		order = append(order, l)
+		return nil
+	}
+
+	for l := range c {
+		if err := visit(l); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
+
+// assignAdvice runs the hint of the advice layer `l` and assigns all the advice layers
+// sharing the same hint call, i.e. the same hint and the same inputs. The input layers have `n` instances.
+func (c Circuit) assignAdvice(a Assignment, l, n int) {
+	hint, err := HintFromName(c[l].Advice.Hint)
+	if err != nil {
+		panic(err)
+	}
+
+	siblings := []int{}
+	nbOutputs := 0
+	for k := 0; k < c.InputArity(); k++ {
+		if c.IsAdviceLayer(k) && c[k].Advice.Hint == c[l].Advice.Hint && equalInts(c[k].Advice.In, c[l].Advice.In) {
+			siblings = append(siblings, k)
+			if c[k].Advice.Output >= nbOutputs {
+				nbOutputs = c[k].Advice.Output + 1
+			}
+		}
+	}
+
+	outputs := make([][]fr.Element, nbOutputs)
+	for i := range outputs {
+		outputs[i] = poly.MakeLarge(n)
+	}
+
+	inputs := make([][]fr.Element, len(c[l].Advice.In))
+	for k, inp := range c[l].Advice.In {
+		inputs[k] = a[inp]
+	}
+
+	if err := hint(outputs, inputs...); err != nil {
+		panic(fmt.Sprintf("the hint %v of layer %v failed : %v", c[l].Advice.Hint, l, err))
+	}
+
+	for _, k := range siblings {
+		a[k] = outputs[c[k].Advice.Output]
+	}
+}
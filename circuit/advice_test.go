@@ -0,0 +1,162 @@
+package circuit_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/consensys/gkr-mimc/circuit"
+	"github.com/consensys/gkr-mimc/circuit/gates"
+	"github.com/consensys/gkr-mimc/common"
+	"github.com/consensys/gkr-mimc/poly"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/stretchr/testify/assert"
+)
+
+// inverseTestCircuit computes x * y and checks its inverse given as advice :
+// the output is zero everywhere if x * y is never zero
+func inverseTestCircuit() (c circuit.Circuit, b *circuit.Builder, prod, inv circuit.Wire) {
+	b = circuit.NewBuilder()
+	x, y := b.Input(), b.Input()
+	prod = b.Apply(gates.MulGate{}, x, y)
+	inv = b.Advice(circuit.InverseHint, 1, prod)[0]
+	one := gates.Constant(fr.NewElement(1))
+	check := gates.FromExpression(gates.Sub(gates.Prod(gates.Input(0), gates.Input(1)), one))
+	b.Output(b.Apply(check, prod, inv))
+
+	c, err := b.Build()
+	if err != nil {
+		panic(err)
+	}
+	return c, b, prod, inv
+}
+
+func TestAdvice(t *testing.T) {
+
+	c, b, prod, inv := inverseTestCircuit()
+
+	// The advice layer is an input layer, but it is not passed to `Assign`
+	assert.Equal(t, 3, c.InputArity())
+	assert.Equal(t, 2, c.ExternalInputArity())
+	assert.Equal(t, 2, b.LayerOf(inv))
+	assert.True(t, c.IsAdviceLayer(b.LayerOf(inv)))
+	assert.Equal(t, []int{b.LayerOf(prod)}, c[b.LayerOf(inv)].Advice.In)
+
+	xs, ys := common.RandomFrArray(8), common.RandomFrArray(8)
+	a := c.Assign(xs, ys)
+	assert.NoError(t, a.Check(c))
+
+	output := a[c.OutputLayers()[0]]
+	for z := range output {
+		assert.True(t, output[z].IsZero(), "instance %v", z)
+	}
+
+	inputs := a.Inputs(c)
+	assert.Len(t, inputs, 3)
+	assert.Equal(t, poly.MultiLin(fr.BatchInvert(a[b.LayerOf(prod)])), inputs[2])
+
+	// A zero product has no inverse
+	xs[3].SetZero()
+	a = c.Assign(xs, ys)
+	assert.False(t, a[c.OutputLayers()[0]][3].IsZero())
+
+	assert.Panics(t, func() { c.Assign(xs, ys, ys) })
+
+	// The advice survives the encodings
+	encodedJSON, err := json.Marshal(c)
+	assert.NoError(t, err)
+	var decodedJSON circuit.Circuit
+	assert.NoError(t, json.Unmarshal(encodedJSON, &decodedJSON))
+	assert.Equal(t, c, decodedJSON)
+
+	encodedBin, err := c.MarshalBinary()
+	assert.NoError(t, err)
+	var decodedBin circuit.Circuit
+	assert.NoError(t, decodedBin.UnmarshalBinary(encodedBin))
+	assert.Equal(t, c, decodedBin)
+
+	// The product is read by the hint : it cannot be fused into the check
+	optimized, mapping, err := circuit.Optimize(c, 8)
+	assert.NoError(t, err)
+	assert.NotEqual(t, -1, mapping[b.LayerOf(prod)])
+	assert.Equal(t, []int{mapping[b.LayerOf(prod)]}, optimized[mapping[b.LayerOf(inv)]].Advice.In)
+}
+
+func TestAdviceBits(t *testing.T) {
+
+	var minusOne fr.Element
+	minusOne.SetInt64(-1)
+	coeffs := []fr.Element{fr.NewElement(1), fr.NewElement(2), fr.NewElement(4), fr.NewElement(8), minusOne}
+
+	// Recomposes the bits of x : the output is zero if x fits on 4 bits
+	b := circuit.NewBuilder()
+	x := b.Input()
+	bits := b.Advice(circuit.BitsHint, 4, x)
+	b.Output(b.Apply(gates.NewLinearCombinationGate(coeffs, fr.Element{}), append(bits, x)...))
+	c, err := b.Build()
+	assert.NoError(t, err)
+
+	xs := make([]fr.Element, 4)
+	for z := range xs {
+		xs[z].SetUint64(uint64(6 * z))
+	}
+	a := c.Assign(xs)
+
+	// The second bits of 0, 6, 12, 18
+	assert.Equal(t, []fr.Element{fr.NewElement(0), fr.NewElement(1), fr.NewElement(0), fr.NewElement(1)}, []fr.Element(a[b.LayerOf(bits[1])]))
+	output := a[c.OutputLayers()[0]]
+	for z := 0; z < 3; z++ {
+		assert.True(t, output[z].IsZero(), "instance %v", z)
+	}
+	// 18 does not fit on 4 bits
+	assert.False(t, output[3].IsZero())
+}
+
+func TestAdviceErrors(t *testing.T) {
+	input := circuit.Layer{In: []int{}}
+	mul := circuit.Layer{In: []int{0, 1}, Gate: gates.MulGate{}}
+
+	testCases := []struct {
+		name   string
+		advice *circuit.Advice
+		kind   error
+	}{
+		{name: "cycle", advice: &circuit.Advice{Hint: circuit.InverseHint, In: []int{2}}, kind: circuit.ErrInvalidAdvice},
+		{name: "unknown hint", advice: &circuit.Advice{Hint: "unknown", In: []int{0}}, kind: circuit.ErrInvalidAdvice},
+		{name: "out of range", advice: &circuit.Advice{Hint: circuit.InverseHint, In: []int{3}}, kind: circuit.ErrInputOutOfRange},
+	}
+
+	for _, tc := range testCases {
+		advice := input
+		advice.Advice = tc.advice
+		err := circuit.BuildCircuit(circuit.Circuit{input, advice, mul})
+		assert.True(t, errors.Is(err, tc.kind), "%v : got %v", tc.name, err)
+	}
+
+	// Only the input layers can be computed by hints
+	c := circuit.Circuit{input, input, {In: []int{0, 1}, Gate: gates.MulGate{}, Advice: &circuit.Advice{Hint: circuit.InverseHint}}}
+	assert.True(t, errors.Is(circuit.BuildCircuit(c), circuit.ErrInvalidAdvice))
+
+	// The values read by a hint must also be used by the circuit, be they inputs or gates
+	b := circuit.NewBuilder()
+	x, y := b.Input(), b.Input()
+	inv := b.Advice(circuit.InverseHint, 1, x)[0]
+	b.Output(b.Apply(gates.MulGate{}, inv, y))
+	_, err := b.Build()
+	assert.Error(t, err)
+
+	b = circuit.NewBuilder()
+	x, y = b.Input(), b.Input()
+	prod := b.Apply(gates.MulGate{}, x, y)
+	inv = b.Advice(circuit.InverseHint, 1, prod)[0]
+	b.Output(b.Apply(gates.MulGate{}, inv, y))
+	_, err = b.Build()
+	assert.Error(t, err)
+
+	// Outputting the value is enough
+	b.Output(prod)
+	_, err = b.Build()
+	assert.NoError(t, err)
+
+	assert.Panics(t, func() { circuit.NewBuilder().Advice("unknown", 1) })
+}
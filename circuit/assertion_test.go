@@ -0,0 +1,90 @@
+package circuit_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/consensys/gkr-mimc/circuit"
+	"github.com/consensys/gkr-mimc/circuit/gates"
+	"github.com/consensys/gkr-mimc/common"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/stretchr/testify/assert"
+)
+
+// booleanity returns the gate x * (x - 1)
+func booleanity() circuit.Gate {
+	x := gates.Input(0)
+	return gates.FromExpression(gates.Prod(x, gates.Sub(x, gates.Constant(fr.NewElement(1)))))
+}
+
+func TestAssertion(t *testing.T) {
+
+	// Checks that x is boolean, and outputs x * (x - 1) * y
+	b := circuit.NewBuilder()
+	x, y := b.Input(), b.Input()
+	isBool := b.Apply(booleanity(), x)
+	prod := b.Apply(gates.MulGate{}, isBool, y)
+	// The assertion is read by another layer : it needs a copy
+	b.AssertZero(isBool)
+	b.Output(prod)
+
+	c, err := b.Build()
+	assert.NoError(t, err)
+
+	assert.Equal(t, []int{b.OutputLayer(prod)}, c.OutputLayers())
+	assert.Equal(t, []int{b.AssertionLayer(isBool)}, c.AssertionLayers())
+	assert.NotEqual(t, b.LayerOf(isBool), b.AssertionLayer(isBool))
+	assert.Equal(t, 1, c.OutputArity())
+
+	xs := make([]fr.Element, 4)
+	xs[1].SetOne()
+	ys := make([]fr.Element, 4)
+	ys[2].SetUint64(5)
+	a := c.Assign(xs, ys)
+	assert.NoError(t, a.Check(c))
+
+	// A non-boolean value breaks the assertion
+	xs[2].SetUint64(2)
+	a = c.Assign(xs, ys)
+	var assignmentErr *circuit.AssignmentError
+	assert.True(t, errors.As(a.Check(c), &assignmentErr))
+	assert.Equal(t, b.AssertionLayer(isBool), assignmentErr.Layer)
+	assert.Equal(t, 2, assignmentErr.Instance)
+	assert.True(t, assignmentErr.Expected.IsZero())
+
+	// The assertions survive the encodings
+	encodedJSON, err := json.Marshal(c)
+	assert.NoError(t, err)
+	var decodedJSON circuit.Circuit
+	assert.NoError(t, json.Unmarshal(encodedJSON, &decodedJSON))
+	assert.Equal(t, c, decodedJSON)
+
+	encodedBin, err := c.MarshalBinary()
+	assert.NoError(t, err)
+	var decodedBin circuit.Circuit
+	assert.NoError(t, decodedBin.UnmarshalBinary(encodedBin))
+	assert.Equal(t, c, decodedBin)
+
+	assert.Panics(t, func() { b.AssertZero(isBool) })
+	assert.Panics(t, func() { b.Output(isBool) })
+}
+
+func TestAssertionErrors(t *testing.T) {
+	input := circuit.Layer{In: []int{}}
+
+	// Only the layers used by no other layer can be assertions
+	c := circuit.Circuit{input, {In: []int{0}, Gate: gates.IdentityGate{}, Assertion: true}, {In: []int{1}, Gate: gates.IdentityGate{}}}
+	assert.True(t, errors.Is(circuit.BuildCircuit(c), circuit.ErrInvalidAssertion))
+
+	c = circuit.Circuit{{In: []int{}, Assertion: true}, {In: []int{0}, Gate: gates.IdentityGate{}}}
+	assert.True(t, errors.Is(circuit.BuildCircuit(c), circuit.ErrInvalidAssertion))
+
+	// A circuit can have no output but an assertion
+	c = circuit.Circuit{input, {In: []int{0}, Gate: gates.IdentityGate{}, Assertion: true}}
+	assert.NoError(t, circuit.BuildCircuit(c))
+	assert.Empty(t, c.OutputLayers())
+
+	a := c.Assign(common.RandomFrArray(2))
+	assert.Error(t, a.Check(c))
+}
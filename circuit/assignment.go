@@ -1,6 +1,9 @@
 package circuit
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/consensys/gkr-mimc/poly"
 	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
 )
@@ -8,17 +11,52 @@ import (
 // Assigment for a GKR circuit
 type Assignment []poly.MultiLin
 
-// Assign computes the full assignment
+// Assign computes the full assignment. `inps` are the values of the input layers that are
+// not advice layers, in order. The advice layers are computed by running their hints.
 func (c Circuit) Assign(inps ...poly.MultiLin) (a Assignment) {
 
+	if len(inps) != c.ExternalInputArity() {
+		panic(fmt.Sprintf("the circuit has %v inputs but was given %v", c.ExternalInputArity(), len(inps)))
+	}
+
+	for i := range inps {
+		if len(inps[i]) != len(inps[0]) {
+			panic(fmt.Sprintf("all the inputs must have the same size : input %v has size %v but input 0 has size %v", i, len(inps[i]), len(inps[0])))
+		}
+	}
+	if _, err := c.Sizes(len(inps[0])); err != nil {
+		panic(err)
+	}
+
 	a = make(Assignment, len(c))
 
 	// Assigns the provided input layers
-	for i := 0; i < len(inps); i++ {
-		a[i] = inps[i].DeepCopyLarge()
+	for i, k := 0, 0; i < c.InputArity(); i++ {
+		if !c.IsAdviceLayer(i) {
+			a[i] = inps[k].DeepCopyLarge()
+			k++
+		}
+	}
+
+	// The hints may read layers coming after their advice layers
+	order, err := c.evaluationOrder()
+	if err != nil {
+		panic(err)
 	}
 
-	for i := len(inps); i < len(a); i++ {
+	for _, i := range order {
+
+		switch {
+		case a[i] != nil:
+			// An input layer, or an advice layer assigned along with another one
+			continue
+		case c.IsAdviceLayer(i):
+			c.assignAdvice(a, i, len(inps[0]))
+			continue
+		case c.IsFixedLayer(i):
+			a[i] = c[i].FixedValues(len(inps[0]))
+			continue
+		}
 
 		inp := make([][]fr.Element, len(c[i].In))
 		for j := range inp {
@@ -31,6 +69,17 @@ func (c Circuit) Assign(inps ...poly.MultiLin) (a Assignment) {
 	return a
 }
 
+// Inputs returns a copy of the values of all the input layers, advice layers included,
+// as expected by the GKR verifier. It must be called before proving, as the prover
+// modifies the assignment.
+func (a Assignment) Inputs(c Circuit) []poly.MultiLin {
+	res := make([]poly.MultiLin, c.InputArity())
+	for i := range res {
+		res[i] = a[i].DeepCopyLarge()
+	}
+	return res
+}
+
 // InputLayersOf returns the input layers of the layer l
 func (a Assignment) InputsOfLayer(c Circuit, l int) []poly.MultiLin {
 	positions := c[l].In
@@ -62,3 +111,135 @@ func (a Assignment) Dump() {
 		poly.DumpLarge(p)
 	}
 }
+
+// AssignmentError is returned by `Assignment.Check`. It gives the first instance
+// of a layer whose value is not the one computed from its inputs, or of an assertion
+// layer that is not zero.
+type AssignmentError struct {
+	Layer    int
+	Instance int
+	Expected fr.Element
+	Actual   fr.Element
+	// Values of the inputs of the gate for this instance, only filled when using
+	// `DumpInputs`. Wired layers have one entry per term of the sum, data-parallel
+	// layers a single one.
+	Inputs [][]fr.Element
+}
+
+func (e *AssignmentError) Error() string {
+	res := fmt.Sprintf("layer %v, instance %v : expected %v but the assignment has %v", e.Layer, e.Instance, e.Expected.String(), e.Actual.String())
+	for _, inputs := range e.Inputs {
+		values := make([]string, len(inputs))
+		for k := range inputs {
+			values[k] = inputs[k].String()
+		}
+		res += fmt.Sprintf("\n\tinputs [%v]", strings.Join(values, ", "))
+	}
+	return res
+}
+
+// CheckOption is an option of `Assignment.Check`
+type CheckOption func(*checkConfig)
+
+type checkConfig struct {
+	dumpInputs bool
+}
+
+// DumpInputs makes `Assignment.Check` report the inputs of the offending instance
+func DumpInputs() CheckOption {
+	return func(cfg *checkConfig) {
+		cfg.dumpInputs = true
+	}
+}
+
+// Check re-evaluates every non-input layer of the assignment from its inputs, instance
+// per instance using `Gate.Eval`. It returns an `*AssignmentError` for the first layer and
+// instance whose value disagrees or breaks an assertion, or an error if the assignment does not
+// have the shape of `c`.
+// It allows to tell apart a wrong witness (or a buggy `EvalBatch`) from a bug of the prover.
+func (a Assignment) Check(c Circuit, opts ...CheckOption) error {
+	cfg := checkConfig{}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	if len(a) != len(c) {
+		return fmt.Errorf("the assignment has %v layers but the circuit has %v", len(a), len(c))
+	}
+
+	sizes, err := c.Sizes(len(a[0]))
+	if err != nil {
+		return err
+	}
+	for l := range a {
+		if len(a[l]) != sizes[l] {
+			return fmt.Errorf("layer %v has size %v, expected %v", l, len(a[l]), sizes[l])
+		}
+	}
+
+	for l := range c {
+		if c.IsInputLayer(l) {
+			continue
+		}
+
+		if c.IsFixedLayer(l) {
+			for z := range a[l] {
+				if expected := c[l].Fixed[z%len(c[l].Fixed)]; expected != a[l][z] {
+					return &AssignmentError{Layer: l, Instance: z, Expected: expected, Actual: a[l][z]}
+				}
+			}
+			continue
+		}
+
+		// terms[z] lists the inputs of each term summed in the instance `z`
+		terms := make([][][]*fr.Element, sizes[l])
+		if c[l].IsWired() {
+			for _, e := range c[l].Wiring {
+				xs := make([]*fr.Element, len(e.In))
+				for k, inp := range e.In {
+					xs[k] = &a[c[l].In[k]][inp]
+				}
+				terms[e.Out] = append(terms[e.Out], xs)
+			}
+		} else {
+			for z := range terms {
+				xs := make([]*fr.Element, len(c[l].In))
+				for k, inp := range c[l].In {
+					xs[k] = &a[inp][z]
+				}
+				terms[z] = [][]*fr.Element{xs}
+			}
+		}
+
+		var expected, tmp fr.Element
+		for z := range terms {
+			expected.SetZero()
+			for _, xs := range terms[z] {
+				c[l].Gate.Eval(&tmp, xs...)
+				expected.Add(&expected, &tmp)
+			}
+
+			if expected == a[l][z] {
+				if !c[l].Assertion || expected.IsZero() {
+					continue
+				}
+				// The values are consistent, but the assertion does not hold
+				expected.SetZero()
+			}
+
+			err := &AssignmentError{Layer: l, Instance: z, Expected: expected, Actual: a[l][z]}
+			if cfg.dumpInputs {
+				err.Inputs = make([][]fr.Element, len(terms[z]))
+				for i, xs := range terms[z] {
+					err.Inputs[i] = make([]fr.Element, len(xs))
+					for k := range xs {
+						err.Inputs[i][k] = *xs[k]
+					}
+				}
+			}
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,55 @@
+package circuit_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/consensys/gkr-mimc/circuit"
+	"github.com/consensys/gkr-mimc/circuit/gates"
+	"github.com/consensys/gkr-mimc/common"
+	"github.com/consensys/gkr-mimc/poly"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAssignmentCheck(t *testing.T) {
+	b := circuit.NewBuilder()
+	x, y := b.Input(), b.Input()
+	prod := b.Apply(gates.MulGate{}, x, y)
+	pairs := circuit.Wiring{{Out: 0, In: []int{0, 1}}, {Out: 0, In: []int{2, 3}}, {Out: 1, In: []int{1, 2}}}
+	sum := b.ApplySized(gates.AddGate{}, pairs, 2, prod, prod)
+	b.Output(sum)
+	c, err := b.Build()
+	assert.NoError(t, err)
+
+	a := c.Assign(common.RandomFrArray(4), common.RandomFrArray(4))
+	assert.NoError(t, a.Check(c))
+
+	// Corrupts an instance of the product, the first failure is the product itself
+	l := b.LayerOf(prod)
+	a[l][2].SetOne()
+	err = a.Check(c, circuit.DumpInputs())
+
+	var assignmentErr *circuit.AssignmentError
+	assert.True(t, errors.As(err, &assignmentErr), "got %v", err)
+	assert.Equal(t, l, assignmentErr.Layer)
+	assert.Equal(t, 2, assignmentErr.Instance)
+	assert.Equal(t, fr.NewElement(1), assignmentErr.Actual)
+	assert.Equal(t, [][]fr.Element{{a[0][2], a[1][2]}}, assignmentErr.Inputs)
+	assert.True(t, strings.Contains(err.Error(), "inputs"))
+
+	// Once fixed, corrupts an instance of the wired layer
+	a[l] = poly.MultiLin(c[l].Evaluate(a[0], a[1]))
+	a[b.LayerOf(sum)][1].SetZero()
+	err = a.Check(c)
+	assert.True(t, errors.As(err, &assignmentErr), "got %v", err)
+	assert.Equal(t, b.LayerOf(sum), assignmentErr.Layer)
+	assert.Equal(t, 1, assignmentErr.Instance)
+	assert.Nil(t, assignmentErr.Inputs)
+
+	// Wrong shapes
+	assert.Error(t, a[:2].Check(c))
+	a[l] = a[l][:2]
+	assert.Error(t, a.Check(c))
+}
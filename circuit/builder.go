@@ -0,0 +1,410 @@
+package circuit
+
+import (
+	"fmt"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+)
+
+// CopyGateID is the ID of the gate used by the `Builder` to insert copy layers.
+// It is the ID of `gates.IdentityGate`, which must be registered (i.e. the `gates`
+// package must be imported) for the builder to insert copy layers.
+const CopyGateID string = "CopyGate"
+
+// Wire is a handle on a value of the circuit being built by a `Builder`
+type Wire struct {
+	id int
+	b  *Builder
+}
+
+// node is a value of the circuit before it is laid out into layers
+type node struct {
+	gate   Gate
+	in     []int
+	wiring Wiring
+	size   int
+	fixed  []fr.Element
+	// Set for advice nodes, `in` is then empty and `hintIn` lists the nodes read by the hint
+	hint       string
+	hintIn     []int
+	hintOutput int
+}
+
+// isInput returns true if the node is an input layer of the circuit, advice layers included
+func (n node) isInput() bool {
+	return n.gate == nil && n.fixed == nil
+}
+
+// isAdvice returns true if the node is computed by a hint
+func (n node) isAdvice() bool {
+	return len(n.hint) > 0
+}
+
+// Builder helps to construct circuits without manipulating layer indices.
+//
+//	b := NewBuilder()
+//	key, msg := b.Input(), b.Input()
+//	x := b.Apply(gate, key, msg)
+//	b.Output(b.Apply(gate, key, x))
+//	c, err := b.Build()
+//
+// The builder takes care of inserting the copy layers required when an input
+// is used several times, when a gate reads the same value several times, or
+// when an output is also used by other layers.
+type Builder struct {
+	nodes   []node
+	outputs []Wire
+	// Values asserted to be zero
+	assertions []Wire
+	// Maps each node to its layer once the circuit is built
+	layerOf []int
+	// Maps the outputs to their output layer once the circuit is built
+	outputLayerOf map[int]int
+	// Maps the asserted values to their assertion layer once the circuit is built
+	assertionLayerOf map[int]int
+}
+
+// NewBuilder returns an empty builder
+func NewBuilder() *Builder {
+	return &Builder{}
+}
+
+// Input adds an input layer to the circuit. The input layers are numbered
+// in the order of the calls to `Input`, and come first in the built circuit.
+func (b *Builder) Input() Wire {
+	b.nodes = append(b.nodes, node{})
+	return Wire{id: len(b.nodes) - 1, b: b}
+}
+
+// Advice adds `nbOutputs` advice layers whose values are computed by the hint registered
+// under the name `hint`, see `Advice`. The hint reads the values of `inputs`, which can
+// be any wire. The advice layers come after the other input layers in the built circuit.
+//
+// As the hints are not part of the proof, reading a wire from a hint does not count as a use of it :
+// `Build` fails if a wire is only read by hints, instead of being read by a gate, an output or an assertion.
+func (b *Builder) Advice(hint string, nbOutputs int, inputs ...Wire) []Wire {
+	if _, err := HintFromName(hint); err != nil {
+		panic(err)
+	}
+	if nbOutputs < 1 {
+		panic(fmt.Sprintf("the hint %v must have at least one output", hint))
+	}
+
+	in := make([]int, len(inputs))
+	for i, w := range inputs {
+		b.checkWire(w)
+		in[i] = w.id
+	}
+
+	res := make([]Wire, nbOutputs)
+	for i := range res {
+		res[i] = b.advice(hint, i, in)
+	}
+	return res
+}
+
+// advice adds an advice node for the output `output` of the hint, reading the nodes `in`
+func (b *Builder) advice(hint string, output int, in []int) Wire {
+	b.nodes = append(b.nodes, node{hint: hint, hintIn: in, hintOutput: output})
+	return Wire{id: len(b.nodes) - 1, b: b}
+}
+
+// Fixed adds a fixed layer to the circuit, see `Layer.Fixed`. The number of values must be a
+// power of two, and at most the size of the input layers. The fixed layers come right
+// after the input layers in the built circuit.
+func (b *Builder) Fixed(values []fr.Element) Wire {
+	if len(values) == 0 {
+		panic("a fixed layer needs at least one value")
+	}
+	b.nodes = append(b.nodes, node{fixed: append([]fr.Element{}, values...)})
+	return Wire{id: len(b.nodes) - 1, b: b}
+}
+
+// Constant adds a fixed layer whose value `v` is broadcast to all the instances
+func (b *Builder) Constant(v fr.Element) Wire {
+	return b.Fixed([]fr.Element{v})
+}
+
+// Apply adds a layer computing `gate` on `inputs`
+func (b *Builder) Apply(gate Gate, inputs ...Wire) Wire {
+	return b.ApplyWired(gate, nil, inputs...)
+}
+
+// ApplyWired adds a layer computing `gate` on `inputs` following `wiring`.
+// A nil wiring gives a data-parallel layer, as `Apply`.
+func (b *Builder) ApplyWired(gate Gate, wiring Wiring, inputs ...Wire) Wire {
+	return b.ApplySized(gate, wiring, 0, inputs...)
+}
+
+// ApplySized adds a wired layer of `size` instances computing `gate` on `inputs`.
+// A zero size means the same size as the first input, see `Layer.Size`.
+func (b *Builder) ApplySized(gate Gate, wiring Wiring, size int, inputs ...Wire) Wire {
+	if gate == nil {
+		panic("cannot apply a nil gate")
+	}
+	if len(inputs) == 0 {
+		panic(fmt.Sprintf("gate %v is applied on no inputs", gate.ID()))
+	}
+	if g, ok := gate.(interface{ Arity() int }); ok && g.Arity() != len(inputs) {
+		panic(fmt.Sprintf("gate %v takes %v inputs but was given %v", gate.ID(), g.Arity(), len(inputs)))
+	}
+
+	in := make([]int, len(inputs))
+	for i, w := range inputs {
+		b.checkWire(w)
+		in[i] = w.id
+	}
+
+	b.nodes = append(b.nodes, node{gate: gate, in: in, wiring: wiring, size: size})
+	return Wire{id: len(b.nodes) - 1, b: b}
+}
+
+// Output marks `w` as an output of the circuit. It can be called several times
+// to declare several outputs.
+func (b *Builder) Output(w Wire) {
+	b.checkWire(w)
+	if containsWire(b.outputs, w) || containsWire(b.assertions, w) {
+		panic("the wire is already an output or an assertion of the circuit")
+	}
+	b.outputs = append(b.outputs, w)
+}
+
+// AssertZero adds an assertion layer checking that all the instances of `w` are zero, see `Layer.Assertion`.
+// Unlike the outputs, the values of `w` are not passed to the verifier.
+func (b *Builder) AssertZero(w Wire) {
+	b.checkWire(w)
+	if containsWire(b.outputs, w) || containsWire(b.assertions, w) {
+		panic("the wire is already an output or an assertion of the circuit")
+	}
+	b.assertions = append(b.assertions, w)
+}
+
+// Build lays out the circuit. The input layers come first, followed by the advice layers,
+// the fixed layers and the copy layers of the inputs used several times, then the other layers in the order
+// they were added, and finally the copy layers of the outputs and the assertions that are
+// also used by other layers. The result is validated by `BuildCircuit`.
+func (b *Builder) Build() (Circuit, error) {
+	if len(b.outputs) == 0 && len(b.assertions) == 0 {
+		return nil, fmt.Errorf("the circuit has no output")
+	}
+
+	c := Circuit{}
+	b.layerOf = make([]int, len(b.nodes))
+	b.outputLayerOf = make(map[int]int, len(b.outputs))
+	b.assertionLayerOf = make(map[int]int, len(b.assertions))
+
+	// Counts the number of times each node is read
+	nbReads := make([]int, len(b.nodes))
+	for _, n := range b.nodes {
+		for _, inp := range n.in {
+			nbReads[inp]++
+		}
+	}
+
+	isOutput := make([]bool, len(b.nodes))
+	for _, o := range append(append([]Wire{}, b.outputs...), b.assertions...) {
+		isOutput[o.id] = true
+		// Outputs and assertions are read from a copy layer for inputs and fixed layers
+		if b.nodes[o.id].gate == nil {
+			nbReads[o.id]++
+		}
+	}
+
+	// The hints are not part of the proof : what they read must also be used by the circuit
+	for _, n := range b.nodes {
+		for _, inp := range n.hintIn {
+			if nbReads[inp] == 0 && !isOutput[inp] {
+				return nil, fmt.Errorf("node %v is only read by the hint %v, it must also be read by a gate or be an output", inp, n.hint)
+			}
+		}
+	}
+
+	for id, n := range b.nodes {
+		if n.gate != nil && nbReads[id] == 0 && !isOutput[id] {
+			return nil, fmt.Errorf("the value of node %v (gate %v) is never used and is not an output", id, n.gate.ID())
+		}
+	}
+
+	// Input layers first, then the advice and the fixed layers
+	for id, n := range b.nodes {
+		if n.isInput() && !n.isAdvice() {
+			b.layerOf[id] = len(c)
+			c = append(c, Layer{In: []int{}})
+		}
+	}
+	for id, n := range b.nodes {
+		if n.isAdvice() {
+			if nbReads[id] == 0 {
+				return nil, fmt.Errorf("the advice node %v (hint %v) is never used", id, n.hint)
+			}
+			b.layerOf[id] = len(c)
+			c = append(c, Layer{In: []int{}})
+		}
+	}
+	for id, n := range b.nodes {
+		if n.fixed != nil {
+			if nbReads[id] == 0 {
+				return nil, fmt.Errorf("the fixed node %v is never used", id)
+			}
+			b.layerOf[id] = len(c)
+			c = append(c, Layer{In: []int{}, Fixed: n.fixed})
+		}
+	}
+
+	// readFrom is the layer consumers of a node should read from.
+	// It differs from `layerOf` for the inputs that are copied.
+	readFrom := make([]int, len(b.nodes))
+	for id, n := range b.nodes {
+		readFrom[id] = b.layerOf[id]
+		// Unlike the inputs, the fixed layers can be read several times
+		if n.isInput() && nbReads[id] > 1 {
+			copyLayer, err := b.appendCopy(&c, b.layerOf[id])
+			if err != nil {
+				return nil, err
+			}
+			readFrom[id] = copyLayer
+		}
+	}
+
+	for id, n := range b.nodes {
+		if n.gate == nil {
+			continue
+		}
+
+		in := make([]int, 0, len(n.in))
+		for _, inp := range n.in {
+			pos := readFrom[inp]
+			// A gate cannot read twice the same layer : read from a copy instead
+			if containsInt(in, pos) {
+				var err error
+				if pos, err = b.appendCopy(&c, pos); err != nil {
+					return nil, err
+				}
+			}
+			in = append(in, pos)
+		}
+
+		b.layerOf[id] = len(c)
+		readFrom[id] = len(c)
+		c = append(c, Layer{In: in, Gate: n.gate, Wiring: n.wiring, Size: n.size})
+	}
+
+	// The hints read the layers of the nodes, which are all laid out by now
+	for id, n := range b.nodes {
+		if n.isAdvice() {
+			advice := &Advice{Hint: n.hint, In: make([]int, len(n.hintIn)), Output: n.hintOutput}
+			for k, inp := range n.hintIn {
+				advice.In[k] = b.layerOf[inp]
+			}
+			c[b.layerOf[id]].Advice = advice
+		}
+	}
+
+	// Outputs cannot be read by other layers : they are copied in a dedicated layer if needed
+	for _, o := range b.outputs {
+		if nbReads[o.id] == 0 {
+			b.outputLayerOf[o.id] = b.layerOf[o.id]
+			continue
+		}
+		copyLayer, err := b.appendCopy(&c, readFrom[o.id])
+		if err != nil {
+			return nil, err
+		}
+		b.outputLayerOf[o.id] = copyLayer
+	}
+
+	for _, o := range b.assertions {
+		l := b.layerOf[o.id]
+		if nbReads[o.id] > 0 {
+			var err error
+			if l, err = b.appendCopy(&c, readFrom[o.id]); err != nil {
+				return nil, err
+			}
+		}
+		c[l].Assertion = true
+		b.assertionLayerOf[o.id] = l
+	}
+
+	if err := BuildCircuit(c); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// LayerOf returns the index of the layer of `w` in the built circuit
+// It can only be called after `Build`
+func (b *Builder) LayerOf(w Wire) int {
+	b.checkWire(w)
+	if b.layerOf == nil {
+		panic("the circuit is not built yet")
+	}
+	return b.layerOf[w.id]
+}
+
+// OutputLayer returns the index of the output layer holding the value of `w`.
+// It can differ from `LayerOf(w)` when `w` is also read by other layers.
+// It can only be called after `Build`
+func (b *Builder) OutputLayer(w Wire) int {
+	b.checkWire(w)
+	if b.outputLayerOf == nil {
+		panic("the circuit is not built yet")
+	}
+	res, ok := b.outputLayerOf[w.id]
+	if !ok {
+		panic("the wire is not an output")
+	}
+	return res
+}
+
+// AssertionLayer returns the index of the assertion layer checking the values of `w`.
+// It can differ from `LayerOf(w)` when `w` is also read by other layers.
+// It can only be called after `Build`
+func (b *Builder) AssertionLayer(w Wire) int {
+	b.checkWire(w)
+	if b.assertionLayerOf == nil {
+		panic("the circuit is not built yet")
+	}
+	res, ok := b.assertionLayerOf[w.id]
+	if !ok {
+		panic("the wire is not asserted to be zero")
+	}
+	return res
+}
+
+// appendCopy adds a copy layer of `pos` at the end of the circuit and returns its index
+func (b *Builder) appendCopy(c *Circuit, pos int) (int, error) {
+	copyGate, err := GateFromID(CopyGateID)
+	if err != nil {
+		return 0, fmt.Errorf("could not insert a copy layer (is the `gates` package imported ?) : %v", err)
+	}
+	*c = append(*c, Layer{In: []int{pos}, Gate: copyGate})
+	return len(*c) - 1, nil
+}
+
+// checkWire panics if the wire was not issued by this builder
+func (b *Builder) checkWire(w Wire) {
+	if w.b != b || w.id < 0 || w.id >= len(b.nodes) {
+		panic("the wire was not issued by this builder")
+	}
+}
+
+// containsWire returns true if `w` is in `wires`
+func containsWire(wires []Wire, w Wire) bool {
+	for _, x := range wires {
+		if x.id == w.id {
+			return true
+		}
+	}
+	return false
+}
+
+// containsInt returns true if `x` is in `arr`
+func containsInt(arr []int, x int) bool {
+	for _, y := range arr {
+		if y == x {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,112 @@
+package circuit_test
+
+import (
+	"testing"
+
+	"github.com/consensys/gkr-mimc/circuit"
+	"github.com/consensys/gkr-mimc/circuit/gates"
+	"github.com/consensys/gkr-mimc/common"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuilder(t *testing.T) {
+
+	b := circuit.NewBuilder()
+	x := b.Input()
+	y := b.Input()
+
+	// x is used twice : it needs a copy layer
+	xy := b.Apply(gates.MulGate{}, x, y)
+	xx := b.Apply(gates.AddGate{}, x, xy)
+	// Reading twice the same value requires a copy as well
+	sq := b.Apply(gates.MulGate{}, xx, xx)
+	b.Output(sq)
+
+	c, err := b.Build()
+	assert.NoError(t, err)
+
+	// 2 inputs, 1 copy of x, 3 gates and 1 copy of xx
+	assert.Len(t, c, 7)
+	assert.Equal(t, 0, b.LayerOf(x))
+	assert.Equal(t, 1, b.LayerOf(y))
+	assert.Equal(t, 6, b.LayerOf(sq))
+	assert.Equal(t, []int{2}, c[0].Out)
+	assert.Equal(t, gates.IdentityGate{}, c[2].Gate)
+
+	// Checks the values : (x + x * y)^2
+	xs := common.RandomFrArray(4)
+	ys := common.RandomFrArray(4)
+	a := c.Assign(xs, ys)
+
+	for i := range xs {
+		var expected fr.Element
+		expected.Mul(&xs[i], &ys[i])
+		expected.Add(&expected, &xs[i])
+		expected.Square(&expected)
+		assert.Equal(t, expected, a[b.LayerOf(sq)][i])
+	}
+}
+
+func TestBuilderMultipleOutputs(t *testing.T) {
+
+	b := circuit.NewBuilder()
+	x := b.Input()
+	y := b.Input()
+
+	// xy is both an output and an input of s : it is copied into a dedicated output layer
+	xy := b.Apply(gates.MulGate{}, x, y)
+	s := b.Apply(gates.AddGate{}, xy, y)
+	b.Output(xy)
+	b.Output(s)
+	// An input can be an output as well
+	b.Output(x)
+
+	c, err := b.Build()
+	assert.NoError(t, err)
+
+	// 2 inputs, 2 copies of the inputs, 2 gates and 2 copies of the outputs
+	assert.Len(t, c, 8)
+	assert.Equal(t, b.LayerOf(s), b.OutputLayer(s))
+	assert.NotEqual(t, b.LayerOf(xy), b.OutputLayer(xy))
+	assert.Equal(t, []int{b.OutputLayer(s), b.OutputLayer(xy), b.OutputLayer(x)}, c.OutputLayers())
+	assert.Equal(t, 3, c.OutputArity())
+
+	xs := common.RandomFrArray(4)
+	ys := common.RandomFrArray(4)
+	a := c.Assign(xs, ys)
+
+	for i := range xs {
+		var expected fr.Element
+		expected.Mul(&xs[i], &ys[i])
+		assert.Equal(t, expected, a[b.OutputLayer(xy)][i])
+		expected.Add(&expected, &ys[i])
+		assert.Equal(t, expected, a[b.OutputLayer(s)][i])
+		assert.Equal(t, xs[i], a[b.OutputLayer(x)][i])
+	}
+
+	assert.Panics(t, func() { b.Output(s) })
+	assert.Panics(t, func() { b.OutputLayer(y) })
+}
+
+func TestBuilderErrors(t *testing.T) {
+
+	// No output
+	b := circuit.NewBuilder()
+	b.Apply(gates.IdentityGate{}, b.Input())
+	_, err := b.Build()
+	assert.Error(t, err)
+
+	// Dangling value
+	b = circuit.NewBuilder()
+	x := b.Input()
+	y := b.Apply(gates.IdentityGate{}, x)
+	b.Apply(gates.IdentityGate{}, y)
+	b.Output(y)
+	_, err = b.Build()
+	assert.Error(t, err)
+
+	// Wrong arity and foreign wires
+	assert.Panics(t, func() { b.Apply(gates.MulGate{}, x) })
+	assert.Panics(t, func() { circuit.NewBuilder().Output(x) })
+}
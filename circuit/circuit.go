@@ -20,32 +20,55 @@ type Layer struct {
 	Out []int
 	// Expresses how to build the variable
 	Gate Gate
+	// Optional, sparse wiring predicate of the layer. If nil, the layer is data-parallel
+	Wiring Wiring
+	// Number of instances of a wired layer, it must be a power of two.
+	// Zero means the same size as its first input. Data-parallel layers
+	// always have the same size as their inputs.
+	Size int
+	// Values of a fixed layer. Fixed layers have no inputs and no gate, and unlike the
+	// input layers their values are part of the circuit, so the verifier evaluates them itself.
+	// They have as many instances as the input layers : the instance `z` is `Fixed[z mod len(Fixed)]`.
+	// In particular, a single value is broadcast to all the instances.
+	Fixed []fr.Element
+	// Optional, set for the input layers whose values are computed by a hint during `Assign`
+	// instead of being passed by the caller, see `Advice`
+	Advice *Advice
+	// Marks an assertion layer : a layer used by no other layer whose values must all be zero.
+	// Unlike the output layers, its values are not given to the verifier, which only checks
+	// that the multilinear extension of the layer is zero at a random point.
+	Assertion bool
 }
 
 // BuildCircuit
 // - Computes the Out layers
-// - Ensures there is no input used more than once : multi-instances must be explicitly provided as intermediary layers
+// - Validates the circuit, see `Validate`
+// In particular, it ensures there is no input used more than once : multi-instances must be explicitly provided as intermediary layers
 func BuildCircuit(c Circuit) error {
 	// Computes the output layers
 	for l := range c {
 		for _, pos := range c[l].In {
+			if pos < 0 || pos >= len(c) {
+				return layerErrorf(l, ErrInputOutOfRange, "input %v, the circuit has %v layers", pos, len(c))
+			}
 			c[pos].Out = append(c[pos].Out, l)
 		}
 	}
 
-	// Counts the number of multi-instances
-	for l := range c {
-		if len(c[l].In) == 0 && len(c[l].Out) > 1 {
-			return fmt.Errorf("Layer %v is an input layer but has %v outputs", l, len(c[l].Out))
-		}
-	}
-
-	return nil
+	return c.Validate()
 }
 
 // Evaluate returns the assignment of the next layer
 // It can be multi-threaded
 func (l *Layer) Evaluate(inputs ...[]fr.Element) []fr.Element {
+	if l.IsWired() {
+		size := l.Size
+		if size == 0 {
+			size = len(inputs[0])
+		}
+		return l.Wiring.Evaluate(l.Gate, size, inputs...)
+	}
+
 	nbIterations := len(inputs[0])
 	res := poly.MakeLarge(nbIterations)
 
@@ -63,6 +86,11 @@ func (l *Layer) Evaluate(inputs ...[]fr.Element) []fr.Element {
 	return res
 }
 
+// IsWired returns true if the layer has a wiring predicate, i.e. it is not data-parallel
+func (l *Layer) IsWired() bool {
+	return l.Wiring != nil
+}
+
 // IsInputLayer returns true/false if this is an input layer
 // There are multiple ways of checking a layer is an input or output
 // All of them are checked. This helps as a sanity checks :
@@ -75,7 +103,28 @@ func (c Circuit) IsInputLayer(layer int) bool {
 		panic(fmt.Sprintf("layer %v has no inputs? : %v but also has no gate? : %v", layer, hasNoInputs, hasNogates))
 	}
 
-	return hasNoInputs
+	return hasNoInputs && !c.IsFixedLayer(layer)
+}
+
+// IsFixedLayer returns true if the values of the layer are fixed by the circuit, see `Layer.Fixed`
+func (c Circuit) IsFixedLayer(layer int) bool {
+	return len(c[layer].Fixed) > 0
+}
+
+// FixedValues returns the values of a fixed layer when the input layers have `n` instances
+func (l *Layer) FixedValues(n int) []fr.Element {
+	res := poly.MakeLarge(n)
+	for z := range res {
+		res[z] = l.Fixed[z%len(l.Fixed)]
+	}
+	return res
+}
+
+// EvalFixed returns the evaluation of a fixed layer at `qPrime`. The values are periodic,
+// so only the last coordinates of `qPrime` (the least significant ones) matter.
+func (l *Layer) EvalFixed(qPrime []fr.Element) fr.Element {
+	bits := common.Log2Ceil(len(l.Fixed))
+	return poly.MultiLin(l.Fixed).Evaluate(qPrime[len(qPrime)-bits:])
 }
 
 // Returns the input arity of the circuit
@@ -89,3 +138,123 @@ func (c Circuit) InputArity() int {
 	}
 	return count
 }
+
+// OutputLayers returns the positions of the output layers in increasing order.
+// They are the non-input layers that are not used by any other layer, except the assertion layers.
+func (c Circuit) OutputLayers() []int {
+	res := []int{}
+	for layer := range c {
+		if c.IsOutputLayer(layer) {
+			res = append(res, layer)
+		}
+	}
+	return res
+}
+
+// IsOutputLayer returns true/false if this is an output layer
+func (c Circuit) IsOutputLayer(layer int) bool {
+	return len(c[layer].Out) == 0 && !c.IsInputLayer(layer) && !c.IsFixedLayer(layer) && !c[layer].Assertion
+}
+
+// AssertionLayers returns the positions of the assertion layers in increasing order, see `Layer.Assertion`
+func (c Circuit) AssertionLayers() []int {
+	res := []int{}
+	for layer := range c {
+		if c[layer].Assertion {
+			res = append(res, layer)
+		}
+	}
+	return res
+}
+
+// FinalLayers returns the positions of the layers whose claims are given by the verifier
+// instead of another layer : the output and the assertion layers, in increasing order
+func (c Circuit) FinalLayers() []int {
+	res := []int{}
+	for layer := range c {
+		if c.IsOutputLayer(layer) || c[layer].Assertion {
+			res = append(res, layer)
+		}
+	}
+	return res
+}
+
+// Returns the output arity of the circuit
+func (c Circuit) OutputArity() int {
+	return len(c.OutputLayers())
+}
+
+// Sizes returns the number of instances of each layer when the input layers have `n` instances.
+// It returns an error if the sizes of the inputs of a data-parallel layer differ, or if a
+// wiring refers to instances out of range.
+func (c Circuit) Sizes(n int) ([]int, error) {
+	if n < 1 || n&(n-1) != 0 {
+		return nil, fmt.Errorf("the size of the input layers must be a power of two, got %v", n)
+	}
+
+	res := make([]int, len(c))
+	for l := range c {
+		if c.IsInputLayer(l) {
+			res[l] = n
+			continue
+		}
+
+		if c.IsFixedLayer(l) {
+			if len(c[l].Fixed) > n {
+				return nil, layerErrorf(l, ErrInconsistentSizes, "has %v fixed values but the input layers have size %v", len(c[l].Fixed), n)
+			}
+			res[l] = n
+			continue
+		}
+
+		inSizes := make([]int, len(c[l].In))
+		for k, inp := range c[l].In {
+			inSizes[k] = res[inp]
+		}
+
+		res[l] = inSizes[0]
+		if !c[l].IsWired() {
+			for k := range inSizes {
+				if inSizes[k] != res[l] {
+					return nil, layerErrorf(l, ErrInconsistentSizes, "input %v has size %v but input 0 has size %v", k, inSizes[k], res[l])
+				}
+			}
+			continue
+		}
+
+		if c[l].Size > 0 {
+			res[l] = c[l].Size
+		}
+		if err := c[l].Wiring.CheckSizes(res[l], inSizes...); err != nil {
+			return nil, layerErrorf(l, ErrInconsistentSizes, "%v", err)
+		}
+	}
+
+	return res, nil
+}
+
+// Bits returns the number of variables of each layer when the input layers have `bN` variables
+func (c Circuit) Bits(bN int) ([]int, error) {
+	if bN < 0 {
+		return nil, fmt.Errorf("the number of variables of the input layers must be non-negative, got %v", bN)
+	}
+	sizes, err := c.Sizes(1 << bN)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]int, len(sizes))
+	for l := range sizes {
+		res[l] = common.Log2Ceil(sizes[l])
+	}
+	return res, nil
+}
+
+// OutputBits returns the number of variables of the largest output or assertion layer,
+// given the number of variables of each layer (see `Bits`)
+func (c Circuit) OutputBits(bits []int) int {
+	res := 0
+	for _, o := range c.FinalLayers() {
+		res = common.Max(res, bits[o])
+	}
+	return res
+}
@@ -0,0 +1,124 @@
+package circuit
+
+import (
+	"fmt"
+)
+
+// Inline adds all the layers of `sub` to the circuit being built. The i-th input layer
+// of `sub` that is not an advice layer is bound to `inputs[i]`, the advice layers are
+// inlined along with their hints. It returns the wires of every layer of `sub` :
+// `res[l]` is the wire of layer `l` of `sub`. In particular, the outputs of `sub`
+// are the wires of `sub.OutputLayers()`. The copy layers of `sub` are inlined as well, and the
+// assertion layers of `sub` are asserted to be zero in the circuit being built.
+func (b *Builder) Inline(sub Circuit, inputs ...Wire) []Wire {
+	nbInputs := sub.ExternalInputArity()
+	if len(inputs) != nbInputs {
+		panic(fmt.Sprintf("the subcircuit has %v inputs but was given %v", nbInputs, len(inputs)))
+	}
+
+	res := make([]Wire, len(sub))
+	for l, k := 0, 0; l < sub.InputArity(); l++ {
+		if sub.IsAdviceLayer(l) {
+			// The hint may read layers that are not inlined yet
+			res[l] = b.advice(sub[l].Advice.Hint, sub[l].Advice.Output, nil)
+			continue
+		}
+		b.checkWire(inputs[k])
+		res[l] = inputs[k]
+		k++
+	}
+
+	for l := sub.InputArity(); l < len(sub); l++ {
+		if sub.IsFixedLayer(l) {
+			res[l] = b.Fixed(sub[l].Fixed)
+			continue
+		}
+		in := make([]Wire, len(sub[l].In))
+		for i, pos := range sub[l].In {
+			in[i] = res[pos]
+		}
+		res[l] = b.ApplySized(sub[l].Gate, sub[l].Wiring, sub[l].Size, in...)
+	}
+
+	for l := 0; l < sub.InputArity(); l++ {
+		if sub.IsAdviceLayer(l) {
+			hintIn := make([]int, len(sub[l].Advice.In))
+			for k, pos := range sub[l].Advice.In {
+				hintIn[k] = res[pos].id
+			}
+			b.nodes[res[l].id].hintIn = hintIn
+		}
+	}
+
+	for _, l := range sub.AssertionLayers() {
+		b.AssertZero(res[l])
+	}
+
+	return res
+}
+
+// Chain returns a circuit where the last output layer of `first` is fed into the input layer `at`
+// of `second`. When `first` has several outputs, the chained one is thus the output layer of highest
+// position, see `OutputLayers`. The assertion layers of `first` are not outputs, they are never chained
+// and remain assertions of the resulting circuit. It is an error if `first` has no output.
+//
+// The inputs of the resulting circuit are the inputs of `first`, followed by the other inputs of
+// `second`, in order. Its outputs are the other outputs of `first` and the outputs of `second`. The
+// advice layers are not counted as inputs : `at` is the position of the input among the ones passed
+// to `Assign`.
+//
+// It also returns the positions of the layers of `first` and `second` in the chained circuit.
+// The input layer `at` of `second` is mapped to the chained output layer of `first`.
+func Chain(first, second Circuit, at int) (c Circuit, firstLayers, secondLayers []int, err error) {
+	if at < 0 || at >= second.ExternalInputArity() {
+		return nil, nil, nil, fmt.Errorf("cannot chain on input %v, the second circuit has %v inputs", at, second.ExternalInputArity())
+	}
+
+	firstOutputs := first.OutputLayers()
+	if len(firstOutputs) == 0 {
+		return nil, nil, nil, fmt.Errorf("cannot chain, the first circuit has no output")
+	}
+	chained := firstOutputs[len(firstOutputs)-1]
+
+	b := NewBuilder()
+
+	firstInputs := make([]Wire, first.ExternalInputArity())
+	for i := range firstInputs {
+		firstInputs[i] = b.Input()
+	}
+	firstWires := b.Inline(first, firstInputs...)
+
+	secondInputs := make([]Wire, second.ExternalInputArity())
+	for i := range secondInputs {
+		if i == at {
+			secondInputs[i] = firstWires[chained]
+			continue
+		}
+		secondInputs[i] = b.Input()
+	}
+	secondWires := b.Inline(second, secondInputs...)
+
+	for _, o := range firstOutputs {
+		if o != chained {
+			b.Output(firstWires[o])
+		}
+	}
+	for _, o := range second.OutputLayers() {
+		b.Output(secondWires[o])
+	}
+
+	if c, err = b.Build(); err != nil {
+		return nil, nil, nil, err
+	}
+
+	return c, b.layersOf(firstWires), b.layersOf(secondWires), nil
+}
+
+// layersOf returns the layers of a list of wires in the built circuit
+func (b *Builder) layersOf(wires []Wire) []int {
+	res := make([]int, len(wires))
+	for i, w := range wires {
+		res[i] = b.LayerOf(w)
+	}
+	return res
+}
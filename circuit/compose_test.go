@@ -0,0 +1,93 @@
+package circuit_test
+
+import (
+	"testing"
+
+	"github.com/consensys/gkr-mimc/circuit"
+	"github.com/consensys/gkr-mimc/circuit/gates"
+	"github.com/consensys/gkr-mimc/common"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/stretchr/testify/assert"
+)
+
+// sumCircuit returns the circuit outputting u + v
+func sumCircuit(t *testing.T) circuit.Circuit {
+	b := circuit.NewBuilder()
+	u, v := b.Input(), b.Input()
+	b.Output(b.Apply(gates.AddGate{}, u, v))
+	c, err := b.Build()
+	assert.NoError(t, err)
+	return c
+}
+
+func TestChain(t *testing.T) {
+
+	// Two outputs : x * y and x + y
+	b := circuit.NewBuilder()
+	x, y := b.Input(), b.Input()
+	b.Output(b.Apply(gates.MulGate{}, x, y))
+	b.Output(b.Apply(gates.AddGate{}, x, y))
+	first, err := b.Build()
+	assert.NoError(t, err)
+	outputs := first.OutputLayers()
+	assert.Len(t, outputs, 2)
+
+	// The last output is chained, the other one stays an output
+	c, firstLayers, secondLayers, err := circuit.Chain(first, sumCircuit(t), 0)
+	assert.NoError(t, err)
+	chained, other := firstLayers[outputs[1]], firstLayers[outputs[0]]
+	assert.Equal(t, chained, secondLayers[0])
+	assert.Contains(t, c.OutputLayers(), other)
+	assert.NotContains(t, c.OutputLayers(), chained)
+	assert.Equal(t, 2, c.OutputArity())
+
+	xs, ys, vs := common.RandomFrArray(4), common.RandomFrArray(4), common.RandomFrArray(4)
+	a := c.Assign(xs, ys, vs)
+	firstA := first.Assign(xs, ys)
+	for i := range xs {
+		assert.Equal(t, firstA[outputs[1]][i], a[chained][i])
+		assert.Equal(t, firstA[outputs[0]][i], a[other][i])
+		var expected fr.Element
+		expected.Add(&a[chained][i], &vs[i])
+		assert.Equal(t, expected, a[secondLayers[2]][i])
+	}
+}
+
+func TestChainAssertion(t *testing.T) {
+
+	// Outputs x + y and asserts that x - y is zero : the assertion layer comes after the output
+	b := circuit.NewBuilder()
+	x, y := b.Input(), b.Input()
+	sum := b.Apply(gates.AddGate{}, x, y)
+	diff := b.Apply(gates.FromExpression(gates.Sub(gates.Input(0), gates.Input(1))), x, y)
+	b.Output(sum)
+	b.AssertZero(diff)
+	first, err := b.Build()
+	assert.NoError(t, err)
+	assert.Less(t, b.OutputLayer(sum), b.AssertionLayer(diff))
+	assert.Equal(t, len(first)-1, b.AssertionLayer(diff))
+
+	c, firstLayers, secondLayers, err := circuit.Chain(first, sumCircuit(t), 0)
+	assert.NoError(t, err)
+	assert.Equal(t, firstLayers[b.OutputLayer(sum)], secondLayers[0])
+	assert.Equal(t, []int{secondLayers[2]}, c.OutputLayers())
+	assert.Equal(t, []int{firstLayers[b.AssertionLayer(diff)]}, c.AssertionLayers())
+
+	xs, vs := common.RandomFrArray(4), common.RandomFrArray(4)
+	a := c.Assign(xs, xs, vs)
+	assert.NoError(t, a.Check(c))
+	for i := range xs {
+		var expected fr.Element
+		expected.Double(&xs[i]).Add(&expected, &vs[i])
+		assert.Equal(t, expected, a[secondLayers[2]][i])
+	}
+
+	// A circuit with only an assertion has nothing to chain
+	b = circuit.NewBuilder()
+	x = b.Input()
+	b.AssertZero(b.Apply(gates.IdentityGate{}, x))
+	noOutput, err := b.Build()
+	assert.NoError(t, err)
+	_, _, _, err = circuit.Chain(noOutput, sumCircuit(t), 0)
+	assert.Error(t, err)
+}
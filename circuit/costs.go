@@ -0,0 +1,39 @@
+package circuit
+
+// ProofSize returns the number of field elements in a `gkr.Proof` of the circuit
+// when its input layers have `2^bN` instances
+func (c Circuit) ProofSize(bN int) (int, error) {
+	bits, err := c.Bits(bN)
+	if err != nil {
+		return 0, err
+	}
+
+	sumcheckSize := 0
+	claimsSize := 0
+	qPrimeSize := 0
+
+	for l, layer := range c {
+		// If not an input gate, adds the sumchecks
+		if layer.IsWired() {
+			// One round of degree 2 per variable of each input
+			for _, inpL := range layer.In {
+				sumcheckSize += bits[inpL] * 3
+			}
+		} else if layer.Gate != nil {
+			// The degree is deg + 1 (because of the multiplication by `eq(h, q)`)
+			// So the number of coefficients is deg + 2
+			nbCoeffs := layer.Gate.Degree() + 2
+			sumcheckSize += bits[l] * nbCoeffs // size of the sumcheck for the layer
+		}
+
+		claimsSize += len(layer.Out)
+		qPrimeSize += bits[l] * len(layer.Out)
+	}
+
+	// For the output and assertion layers
+	for _, o := range c.FinalLayers() {
+		qPrimeSize += bits[o]
+	}
+
+	return sumcheckSize + claimsSize + qPrimeSize, nil
+}
@@ -0,0 +1,26 @@
+package circuit_test
+
+import (
+	"testing"
+
+	"github.com/consensys/gkr-mimc/circuit"
+	"github.com/consensys/gkr-mimc/circuit/gates"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProofSize(t *testing.T) {
+	b := circuit.NewBuilder()
+	x, y := b.Input(), b.Input()
+	b.Output(b.Apply(gates.MulGate{}, x, y))
+	c, err := b.Build()
+	assert.NoError(t, err)
+
+	// 4 rounds of degree 3, and the qPrimes of the output and the inputs and their claims
+	size, err := c.ProofSize(4)
+	assert.NoError(t, err)
+	assert.Equal(t, 4*4+2*4+2+4, size)
+
+	// The sizes of the inputs must be powers of two
+	_, err = c.ProofSize(-1)
+	assert.Error(t, err)
+}
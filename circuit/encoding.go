@@ -0,0 +1,447 @@
+package circuit
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+)
+
+// EncodingVersion is the version of the JSON and binary encodings of a circuit.
+// It is bumped every time the format changes in a released version.
+const EncodingVersion uint64 = 1
+
+// maxLayerSize is the largest size of a layer accepted by the binary decoder
+const maxLayerSize uint64 = 1 << 40
+
+// checkVersion returns an error if the encoding version is not supported
+func checkVersion(version uint64) error {
+	if version != EncodingVersion {
+		return fmt.Errorf("unsupported circuit encoding version %v, expected %v", version, EncodingVersion)
+	}
+	return nil
+}
+
+// circuitJSON is the JSON representation of a circuit
+type circuitJSON struct {
+	Version uint64  `json:"version"`
+	Layers  []Layer `json:"layers"`
+}
+
+// layerJSON is the JSON representation of a layer
+// `Out` is not encoded, as it is entirely determined by the `In` of the other layers
+// Each entry of the wiring is encoded as [Out, In...], and the fixed values as decimal strings
+type layerJSON struct {
+	In        []int    `json:"in"`
+	Gate      string   `json:"gate,omitempty"`
+	Wiring    [][]int  `json:"wiring,omitempty"`
+	Size      int      `json:"size,omitempty"`
+	Fixed     []string `json:"fixed,omitempty"`
+	Advice    *Advice  `json:"advice,omitempty"`
+	Assertion bool     `json:"assertion,omitempty"`
+}
+
+// MarshalJSON encodes the layer as {"in": [...], "gate": "<gate ID>", "wiring": [[out, in...], ...], "size": n, "fixed": [...], "advice": {...}, "assertion": true}
+// The gate is omitted for input and fixed layers, the wiring for data-parallel layers, the size when it is zero,
+// the fixed values for the layers that are not fixed, the advice for the layers not computed by a hint
+// and the assertion flag for the layers that are not assertions.
+func (l Layer) MarshalJSON() ([]byte, error) {
+	res := layerJSON{In: l.In, Size: l.Size, Advice: l.Advice, Assertion: l.Assertion}
+	if res.In == nil {
+		res.In = []int{}
+	}
+	if l.Gate != nil {
+		res.Gate = l.Gate.ID()
+	}
+	for i := range l.Fixed {
+		res.Fixed = append(res.Fixed, l.Fixed[i].String())
+	}
+	for _, e := range l.Wiring {
+		res.Wiring = append(res.Wiring, append([]int{e.Out}, e.In...))
+	}
+	return json.Marshal(res)
+}
+
+// UnmarshalJSON decodes a layer. The gate is rebuilt using the gate registry.
+// `Out` is left empty : it is recomputed when decoding the whole circuit.
+func (l *Layer) UnmarshalJSON(data []byte) error {
+	var decoded layerJSON
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+
+	l.In = decoded.In
+	if l.In == nil {
+		l.In = []int{}
+	}
+	l.Out = nil
+	l.Gate = nil
+	l.Wiring = nil
+	l.Size = decoded.Size
+	l.Fixed = nil
+	l.Advice = decoded.Advice
+	l.Assertion = decoded.Assertion
+
+	for i, x := range decoded.Fixed {
+		// `fr.Element.SetString` panics on invalid inputs
+		v, ok := new(big.Int).SetString(x, 10)
+		if !ok {
+			return fmt.Errorf("fixed value %v is not a decimal number : %q", i, x)
+		}
+		// Only the canonical representation is accepted, so that a circuit has a single encoding
+		if v.Sign() < 0 || v.Cmp(fr.Modulus()) >= 0 {
+			return fmt.Errorf("fixed value %v is not reduced modulo the field size : %v", i, x)
+		}
+		var e fr.Element
+		l.Fixed = append(l.Fixed, *e.SetBigInt(v))
+	}
+
+	for i, e := range decoded.Wiring {
+		if len(e) < 1 {
+			return fmt.Errorf("wiring entry %v is empty", i)
+		}
+		l.Wiring = append(l.Wiring, WiringEntry{Out: e[0], In: e[1:]})
+	}
+
+	if len(decoded.Gate) > 0 {
+		gate, err := GateFromID(decoded.Gate)
+		if err != nil {
+			return err
+		}
+		l.Gate = gate
+	}
+
+	return nil
+}
+
+// MarshalJSON encodes the circuit along with the version of the encoding
+func (c Circuit) MarshalJSON() ([]byte, error) {
+	return json.Marshal(circuitJSON{Version: EncodingVersion, Layers: []Layer(c)})
+}
+
+// UnmarshalJSON decodes a circuit and recomputes the `Out` of every layer
+func (c *Circuit) UnmarshalJSON(data []byte) error {
+	var decoded circuitJSON
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+
+	if err := checkVersion(decoded.Version); err != nil {
+		return err
+	}
+
+	return c.setDecodedLayers(decoded.Layers)
+}
+
+// MarshalBinary returns a compact encoding of the circuit. The layout is
+//
+//	version || nLayers || for each layer : (len(In) || In... || len(gateID) || gateID || fixed || advice || assertion || wiring)
+//
+// Where all the integers are encoded as uvarints. An empty gateID means an input or a fixed layer.
+// The fixed values are encoded as len(Fixed) followed by the big-endian bytes of each value.
+// The advice is encoded as len(hint) || hint || len(In) || In... || Output, or 0 for the other layers.
+// The assertion flag is encoded as 1 for the assertion layers and 0 otherwise.
+// The wiring is encoded as 0 for data-parallel layers, and otherwise as
+// (len(Wiring) + 1) || Size followed by (Out || In...) for each entry.
+func (c Circuit) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	var tmp [binary.MaxVarintLen64]byte
+
+	writeUvarint := func(x uint64) {
+		n := binary.PutUvarint(tmp[:], x)
+		buf.Write(tmp[:n])
+	}
+
+	writeUvarint(EncodingVersion)
+	writeUvarint(uint64(len(c)))
+
+	for l := range c {
+		writeUvarint(uint64(len(c[l].In)))
+		for _, inp := range c[l].In {
+			if inp < 0 {
+				return nil, fmt.Errorf("layer %v has a negative input %v", l, inp)
+			}
+			writeUvarint(uint64(inp))
+		}
+
+		gateID := ""
+		if c[l].Gate != nil {
+			gateID = c[l].Gate.ID()
+		}
+		writeUvarint(uint64(len(gateID)))
+		buf.WriteString(gateID)
+
+		writeUvarint(uint64(len(c[l].Fixed)))
+		for i := range c[l].Fixed {
+			b := c[l].Fixed[i].Bytes()
+			buf.Write(b[:])
+		}
+
+		if !c.IsAdviceLayer(l) {
+			writeUvarint(0)
+		} else {
+			advice := c[l].Advice
+			if len(advice.Hint) == 0 || advice.Output < 0 {
+				return nil, fmt.Errorf("layer %v has an invalid advice %v", l, *advice)
+			}
+			writeUvarint(uint64(len(advice.Hint)))
+			buf.WriteString(advice.Hint)
+			writeUvarint(uint64(len(advice.In)))
+			for _, inp := range advice.In {
+				if inp < 0 {
+					return nil, fmt.Errorf("layer %v : the hint reads a negative layer %v", l, inp)
+				}
+				writeUvarint(uint64(inp))
+			}
+			writeUvarint(uint64(advice.Output))
+		}
+
+		if c[l].Assertion {
+			writeUvarint(1)
+		} else {
+			writeUvarint(0)
+		}
+
+		if !c[l].IsWired() {
+			writeUvarint(0)
+			continue
+		}
+		writeUvarint(uint64(len(c[l].Wiring)) + 1)
+		if c[l].Size < 0 {
+			return nil, fmt.Errorf("layer %v has a negative size %v", l, c[l].Size)
+		}
+		writeUvarint(uint64(c[l].Size))
+		for i, e := range c[l].Wiring {
+			if len(e.In) != len(c[l].In) {
+				return nil, fmt.Errorf("layer %v : wiring entry %v has %v inputs, expected %v", l, i, len(e.In), len(c[l].In))
+			}
+			for _, x := range append([]int{e.Out}, e.In...) {
+				if x < 0 {
+					return nil, fmt.Errorf("layer %v : wiring entry %v has a negative instance %v", l, i, x)
+				}
+				writeUvarint(uint64(x))
+			}
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary decodes a circuit encoded by `MarshalBinary` and recomputes the `Out` of every layer
+func (c *Circuit) UnmarshalBinary(data []byte) error {
+	r := bytes.NewReader(data)
+
+	readUvarint := func(what string) (uint64, error) {
+		x, err := binary.ReadUvarint(r)
+		if err != nil {
+			return 0, fmt.Errorf("could not read %v : %v", what, err)
+		}
+		return x, nil
+	}
+
+	version, err := readUvarint("the version")
+	if err != nil {
+		return err
+	}
+	if err := checkVersion(version); err != nil {
+		return err
+	}
+
+	nLayers, err := readUvarint("the number of layers")
+	if err != nil {
+		return err
+	}
+	// Each layer takes at least two bytes : prevents huge allocations on corrupted inputs
+	if nLayers > uint64(r.Len()) {
+		return fmt.Errorf("the number of layers %v is inconsistent with the size of the input", nLayers)
+	}
+
+	layers := make([]Layer, nLayers)
+	for l := range layers {
+		nIn, err := readUvarint(fmt.Sprintf("the number of inputs of layer %v", l))
+		if err != nil {
+			return err
+		}
+		if nIn > uint64(r.Len()) {
+			return fmt.Errorf("the number of inputs of layer %v is inconsistent with the size of the input", l)
+		}
+
+		layers[l].In = make([]int, nIn)
+		for i := range layers[l].In {
+			inp, err := readUvarint(fmt.Sprintf("input %v of layer %v", i, l))
+			if err != nil {
+				return err
+			}
+			if inp >= nLayers {
+				return fmt.Errorf("layer %v has input %v but there are only %v layers", l, inp, nLayers)
+			}
+			layers[l].In[i] = int(inp)
+		}
+
+		idLen, err := readUvarint(fmt.Sprintf("the gate of layer %v", l))
+		if err != nil {
+			return err
+		}
+		if idLen > uint64(r.Len()) {
+			return fmt.Errorf("the gate ID of layer %v is longer than the remaining input", l)
+		}
+
+		if idLen > 0 {
+			id := make([]byte, idLen)
+			if _, err := io.ReadFull(r, id); err != nil {
+				return fmt.Errorf("could not read the gate of layer %v : %v", l, err)
+			}
+			gate, err := GateFromID(string(id))
+			if err != nil {
+				return fmt.Errorf("layer %v : %v", l, err)
+			}
+			layers[l].Gate = gate
+		}
+
+		nFixed, err := readUvarint(fmt.Sprintf("the number of fixed values of layer %v", l))
+		if err != nil {
+			return err
+		}
+		if nFixed > uint64(r.Len())/fr.Bytes {
+			return fmt.Errorf("the number of fixed values of layer %v is inconsistent with the size of the input", l)
+		}
+		if nFixed > 0 {
+			layers[l].Fixed = make([]fr.Element, nFixed)
+		}
+		var b [fr.Bytes]byte
+		for i := range layers[l].Fixed {
+			if _, err := io.ReadFull(r, b[:]); err != nil {
+				return fmt.Errorf("could not read fixed value %v of layer %v : %v", i, l, err)
+			}
+			// `SetBytes` reduces the value : only the canonical encoding is accepted
+			if canonical := layers[l].Fixed[i].SetBytes(b[:]).Bytes(); canonical != b {
+				return fmt.Errorf("fixed value %v of layer %v is not reduced modulo the field size", i, l)
+			}
+		}
+
+		if layers[l].Advice, err = readAdvice(r, l, nLayers); err != nil {
+			return err
+		}
+
+		assertion, err := readUvarint(fmt.Sprintf("the assertion flag of layer %v", l))
+		if err != nil {
+			return err
+		}
+		if assertion > 1 {
+			return fmt.Errorf("invalid assertion flag %v for layer %v", assertion, l)
+		}
+		layers[l].Assertion = assertion == 1
+
+		nEntries, err := readUvarint(fmt.Sprintf("the wiring of layer %v", l))
+		if err != nil {
+			return err
+		}
+		if nEntries == 0 {
+			continue
+		}
+		nEntries--
+
+		size, err := readUvarint(fmt.Sprintf("the size of layer %v", l))
+		if err != nil {
+			return err
+		}
+		if size > maxLayerSize {
+			return fmt.Errorf("the size %v of layer %v is too large", size, l)
+		}
+		layers[l].Size = int(size)
+
+		if nEntries > uint64(r.Len()) {
+			return fmt.Errorf("the size of the wiring of layer %v is inconsistent with the size of the input", l)
+		}
+
+		layers[l].Wiring = make(Wiring, nEntries)
+		for i := range layers[l].Wiring {
+			entry := make([]int, len(layers[l].In)+1)
+			for j := range entry {
+				x, err := readUvarint(fmt.Sprintf("wiring entry %v of layer %v", i, l))
+				if err != nil {
+					return err
+				}
+				entry[j] = int(x)
+			}
+			layers[l].Wiring[i] = WiringEntry{Out: entry[0], In: entry[1:]}
+		}
+	}
+
+	if r.Len() > 0 {
+		return fmt.Errorf("%v trailing bytes after the circuit", r.Len())
+	}
+
+	return c.setDecodedLayers(layers)
+}
+
+// readAdvice reads the advice of the layer `l` in a circuit of `nLayers` layers, see `MarshalBinary`.
+// It returns nil for the layers that are not computed by a hint.
+func readAdvice(r *bytes.Reader, l int, nLayers uint64) (*Advice, error) {
+	readUvarint := func(what string) (uint64, error) {
+		x, err := binary.ReadUvarint(r)
+		if err != nil {
+			return 0, fmt.Errorf("could not read %v of layer %v : %v", what, l, err)
+		}
+		return x, nil
+	}
+
+	hintLen, err := readUvarint("the hint")
+	if err != nil || hintLen == 0 {
+		return nil, err
+	}
+	if hintLen > uint64(r.Len()) {
+		return nil, fmt.Errorf("the hint of layer %v is longer than the remaining input", l)
+	}
+	hint := make([]byte, hintLen)
+	if _, err := io.ReadFull(r, hint); err != nil {
+		return nil, fmt.Errorf("could not read the hint of layer %v : %v", l, err)
+	}
+
+	nIn, err := readUvarint("the number of hint inputs")
+	if err != nil {
+		return nil, err
+	}
+	if nIn > uint64(r.Len()) {
+		return nil, fmt.Errorf("the number of hint inputs of layer %v is inconsistent with the size of the input", l)
+	}
+
+	res := &Advice{Hint: string(hint), In: make([]int, nIn)}
+	for i := range res.In {
+		inp, err := readUvarint(fmt.Sprintf("hint input %v", i))
+		if err != nil {
+			return nil, err
+		}
+		if inp >= nLayers {
+			return nil, fmt.Errorf("the hint of layer %v reads layer %v but there are only %v layers", l, inp, nLayers)
+		}
+		res.In[i] = int(inp)
+	}
+
+	output, err := readUvarint("the hint output")
+	if err != nil {
+		return nil, err
+	}
+	if output > maxLayerSize {
+		return nil, fmt.Errorf("the hint output %v of layer %v is too large", output, l)
+	}
+	res.Output = int(output)
+
+	return res, nil
+}
+
+// setDecodedLayers recomputes the `Out` fields of freshly decoded layers,
+// validates the result and sets it in `c`
+func (c *Circuit) setDecodedLayers(layers []Layer) error {
+	res := Circuit(layers)
+	if err := BuildCircuit(res); err != nil {
+		return err
+	}
+
+	*c = res
+	return nil
+}
@@ -0,0 +1,110 @@
+package circuit
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// layerKind returns a short description of the role of the layer in the circuit
+func (c Circuit) layerKind(l int) string {
+	switch {
+	case c.IsFixedLayer(l):
+		return "fixed"
+	case c.IsAdviceLayer(l):
+		return "advice"
+	case c[l].Assertion:
+		return "assertion"
+	case len(c[l].In) == 0:
+		return "input"
+	case len(c[l].Out) == 0:
+		return "output"
+	default:
+		return "internal"
+	}
+}
+
+// WriteText writes a human-readable description of the circuit, with one line per layer
+// giving its index, its role, its gate, the degree of the gate and its `In` and `Out`.
+func (c Circuit) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "LAYER\tKIND\tGATE\tDEGREE\tIN\tOUT")
+
+	for l := range c {
+		gate, degree := "-", "-"
+		if c[l].Gate != nil {
+			gate = c[l].Gate.ID()
+			degree = fmt.Sprintf("%v", c[l].Gate.Degree())
+		}
+		fmt.Fprintf(tw, "%v\t%v\t%v\t%v\t%v\t%v\n", l, c.layerKind(l), gate, degree, c[l].In, c[l].Out)
+	}
+
+	return tw.Flush()
+}
+
+// String returns the description of the circuit written by `WriteText`
+func (c Circuit) String() string {
+	var sb strings.Builder
+	// Writing in a strings.Builder never fails
+	_ = c.WriteText(&sb)
+	return sb.String()
+}
+
+// WriteDOT writes the circuit as a graphviz digraph. Each layer is a node labelled
+// with its index, gate and degree. The edges go from the inputs of a layer to
+// the layer, and are labelled with the position of the input for the gate.
+// Input layers are drawn as boxes, fixed layers as diamonds, output layers as double circles
+// and assertion layers as double octagons.
+// The values read by the hints of the advice layers are drawn as dashed edges.
+func (c Circuit) WriteDOT(w io.Writer) error {
+	var sb strings.Builder
+
+	sb.WriteString("digraph circuit {\n")
+	sb.WriteString("\tnode [shape=ellipse];\n")
+
+	for l := range c {
+		label := fmt.Sprintf("%v: input", l)
+		shape := "box"
+		if c[l].Gate != nil {
+			label = fmt.Sprintf("%v: %v\\ndegree %v", l, escapeDOT(c[l].Gate.ID()), c[l].Gate.Degree())
+			shape = "ellipse"
+		}
+		if c.IsFixedLayer(l) {
+			label = fmt.Sprintf("%v: fixed (%v values)", l, len(c[l].Fixed))
+			shape = "diamond"
+		}
+		if c.IsAdviceLayer(l) {
+			label = fmt.Sprintf("%v: advice\\n%v (output %v)", l, escapeDOT(c[l].Advice.Hint), c[l].Advice.Output)
+		}
+		if len(c[l].In) > 0 && len(c[l].Out) == 0 {
+			shape = "doublecircle"
+		}
+		if c[l].Assertion {
+			shape = "doubleoctagon"
+		}
+		fmt.Fprintf(&sb, "\tl%v [label=\"%v\", shape=%v];\n", l, label, shape)
+	}
+
+	for l := range c {
+		for pos, inp := range c[l].In {
+			fmt.Fprintf(&sb, "\tl%v -> l%v [label=\"%v\"];\n", inp, l, pos)
+		}
+		// The values read by the hints are dashed
+		if c.IsAdviceLayer(l) {
+			for pos, inp := range c[l].Advice.In {
+				fmt.Fprintf(&sb, "\tl%v -> l%v [label=\"%v\", style=dashed];\n", inp, l, pos)
+			}
+		}
+	}
+
+	sb.WriteString("}\n")
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// escapeDOT escapes a string so it can be put in a quoted DOT label
+func escapeDOT(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s)
+}
@@ -0,0 +1,32 @@
+package circuit_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/consensys/gkr-mimc/circuit"
+	"github.com/consensys/gkr-mimc/circuit/gates"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExport(t *testing.T) {
+	b := circuit.NewBuilder()
+	x, y := b.Input(), b.Input()
+	b.Output(b.Apply(gates.MulGate{}, x, y))
+	c, err := b.Build()
+	assert.NoError(t, err)
+
+	text := c.String()
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+	assert.Len(t, lines, 4)
+	assert.Regexp(t, `^0\s+input\s+-\s+-\s+\[\]\s+\[2\]$`, lines[1])
+	assert.Regexp(t, `^2\s+output\s+MulGate\s+2\s+\[0 1\]\s+\[\]$`, lines[3])
+
+	var sb strings.Builder
+	assert.NoError(t, c.WriteDOT(&sb))
+	dot := sb.String()
+	assert.True(t, strings.HasPrefix(dot, "digraph circuit {"))
+	assert.Contains(t, dot, `l2 [label="2: MulGate\ndegree 2", shape=doublecircle];`)
+	assert.Contains(t, dot, `l0 -> l2 [label="0"];`)
+	assert.Contains(t, dot, `l1 -> l2 [label="1"];`)
+}
@@ -0,0 +1,108 @@
+package circuit_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/consensys/gkr-mimc/circuit"
+	"github.com/consensys/gkr-mimc/circuit/gates"
+	"github.com/consensys/gkr-mimc/common"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/stretchr/testify/assert"
+)
+
+// fixedTestCircuit computes x * sel + c where sel alternates between 0 and 1
+// and c is a broadcast constant
+func fixedTestCircuit() (c circuit.Circuit, b *circuit.Builder, sel circuit.Wire) {
+	b = circuit.NewBuilder()
+	x := b.Input()
+	sel = b.Fixed([]fr.Element{fr.NewElement(0), fr.NewElement(1)})
+	cst := b.Constant(fr.NewElement(5))
+	res := b.Apply(gates.AddGate{}, b.Apply(gates.MulGate{}, x, sel), cst)
+	b.Output(res)
+
+	c, err := b.Build()
+	if err != nil {
+		panic(err)
+	}
+	return c, b, sel
+}
+
+func TestFixed(t *testing.T) {
+
+	c, b, sel := fixedTestCircuit()
+
+	// The fixed layers come right after the inputs
+	assert.Equal(t, 1, c.InputArity())
+	assert.Equal(t, 1, b.LayerOf(sel))
+	assert.True(t, c.IsFixedLayer(2))
+	assert.False(t, c.IsInputLayer(2))
+	assert.Equal(t, []int{4}, c.OutputLayers())
+
+	n := 8
+	xs := common.RandomFrArray(n)
+	a := c.Assign(xs)
+	assert.NoError(t, a.Check(c))
+
+	five := fr.NewElement(5)
+	for z := range xs {
+		expected := five
+		if z%2 == 1 {
+			expected.Add(&expected, &xs[z])
+		}
+		assert.Equal(t, expected, a[4][z], "instance %v", z)
+	}
+
+	// The fixed values cannot be changed in the assignment
+	a[b.LayerOf(sel)][3].SetZero()
+	var assignmentErr *circuit.AssignmentError
+	assert.True(t, errors.As(a.Check(c), &assignmentErr))
+	assert.Equal(t, b.LayerOf(sel), assignmentErr.Layer)
+	assert.Equal(t, 3, assignmentErr.Instance)
+
+	// The evaluation only depends on the last coordinates
+	qPrime := common.RandomFrArray(3)
+	assert.Equal(t, a[2].Evaluate(qPrime), c[2].EvalFixed(qPrime))
+	a = c.Assign(xs)
+	assert.Equal(t, a[1].Evaluate(qPrime), c[1].EvalFixed(qPrime))
+
+	// More fixed values than instances
+	_, err := c.Sizes(1)
+	assert.True(t, errors.Is(err, circuit.ErrInconsistentSizes))
+
+	// The fixed values survive the encodings
+	encodedJSON, err := json.Marshal(c)
+	assert.NoError(t, err)
+	var decodedJSON circuit.Circuit
+	assert.NoError(t, json.Unmarshal(encodedJSON, &decodedJSON))
+	assert.Equal(t, c, decodedJSON)
+
+	encodedBin, err := c.MarshalBinary()
+	assert.NoError(t, err)
+	var decodedBin circuit.Circuit
+	assert.NoError(t, decodedBin.UnmarshalBinary(encodedBin))
+	assert.Equal(t, c, decodedBin)
+
+	assert.Error(t, decodedJSON.UnmarshalJSON([]byte(`{"version":1,"layers":[{"in":[]},{"in":[],"fixed":["x"]}]}`)))
+
+	// The values must be reduced : 1 + r is rejected in both encodings
+	var onePlusR big.Int
+	onePlusR.Add(fr.Modulus(), big.NewInt(1))
+	assert.Contains(t, string(encodedJSON), `"fixed":["0","1"]`)
+	for _, v := range []string{onePlusR.String(), "-1"} {
+		corrupted := strings.Replace(string(encodedJSON), `"fixed":["0","1"]`, `"fixed":["0","`+v+`"]`, 1)
+		assert.Error(t, json.Unmarshal([]byte(corrupted), &decodedJSON), v)
+	}
+
+	one := fr.NewElement(1)
+	oneBytes := one.Bytes()
+	pos := bytes.Index(encodedBin, oneBytes[:])
+	assert.True(t, pos >= 0)
+	corrupted := append([]byte{}, encodedBin...)
+	onePlusR.FillBytes(corrupted[pos : pos+fr.Bytes])
+	assert.Error(t, decodedBin.UnmarshalBinary(corrupted))
+}
@@ -0,0 +1,179 @@
+package circuit
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/AlexandreBelling/gnark/frontend"
+	"github.com/consensys/gkr-mimc/common"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+)
+
+func init() {
+	RegisterGateConstructor("Fused", newFusedGateFromParams)
+}
+
+// FusedGate is the composition of several gates, as produced by `Optimize`.
+// It returns `Outer(y_0, ..., y_k)` where `y_i = Inners[i](x[Inputs[i][0]], x[Inputs[i][1]], ...)`
+// or directly `y_i = x[Inputs[i][0]]` when `Inners[i]` is nil.
+type FusedGate struct {
+	Outer  Gate
+	Inners []Gate
+	Inputs [][]int
+
+	id       string
+	nbInputs int
+}
+
+// fusedGateParams is the description of a fused gate in its ID
+type fusedGateParams struct {
+	Outer  string   `json:"outer"`
+	Inners []string `json:"inners"`
+	Inputs [][]int  `json:"inputs"`
+}
+
+// NewFusedGate returns the composition of `outer` with `inners`, see `FusedGate`
+func NewFusedGate(outer Gate, inners []Gate, inputs [][]int) *FusedGate {
+	if len(inners) != len(inputs) {
+		panic(fmt.Sprintf("got %v inner gates but %v lists of inputs", len(inners), len(inputs)))
+	}
+
+	params := fusedGateParams{Outer: outer.ID(), Inners: make([]string, len(inners)), Inputs: inputs}
+	nbInputs := 0
+	for i := range inners {
+		if inners[i] == nil && len(inputs[i]) != 1 {
+			panic(fmt.Sprintf("input %v is passed through but reads %v inputs", i, len(inputs[i])))
+		}
+		if inners[i] != nil {
+			params.Inners[i] = inners[i].ID()
+		}
+		for _, x := range inputs[i] {
+			if x < 0 {
+				panic(fmt.Sprintf("negative input index %v", x))
+			}
+			nbInputs = common.Max(nbInputs, x+1)
+		}
+	}
+
+	id, err := json.Marshal(params)
+	if err != nil {
+		panic(err)
+	}
+
+	return &FusedGate{
+		Outer:    outer,
+		Inners:   inners,
+		Inputs:   inputs,
+		id:       "Fused-" + string(id),
+		nbInputs: nbInputs,
+	}
+}
+
+// newFusedGateFromParams is the constructor registered for the fused gates
+func newFusedGateFromParams(params string) (Gate, error) {
+	var p fusedGateParams
+	if err := json.Unmarshal([]byte(params), &p); err != nil {
+		return nil, err
+	}
+	if len(p.Inners) != len(p.Inputs) {
+		return nil, fmt.Errorf("got %v inner gates but %v lists of inputs", len(p.Inners), len(p.Inputs))
+	}
+
+	outer, err := GateFromID(p.Outer)
+	if err != nil {
+		return nil, err
+	}
+
+	inners := make([]Gate, len(p.Inners))
+	for i, id := range p.Inners {
+		if len(id) == 0 {
+			if len(p.Inputs[i]) != 1 {
+				return nil, fmt.Errorf("input %v is passed through but reads %v inputs", i, len(p.Inputs[i]))
+			}
+			continue
+		}
+		if inners[i], err = GateFromID(id); err != nil {
+			return nil, err
+		}
+	}
+
+	for i := range p.Inputs {
+		for _, x := range p.Inputs[i] {
+			if x < 0 {
+				return nil, fmt.Errorf("negative input index %v", x)
+			}
+		}
+	}
+
+	return NewFusedGate(outer, inners, p.Inputs), nil
+}
+
+// ID returns the ID of the gate, it describes the fused gates
+func (f *FusedGate) ID() string { return f.id }
+
+// Arity returns the number of inputs of the gate
+func (f *FusedGate) Arity() int { return f.nbInputs }
+
+// Degree returns an upper-bound on the degree of the composition
+func (f *FusedGate) Degree() int {
+	innerDegree := 1
+	for _, inner := range f.Inners {
+		if inner != nil {
+			innerDegree = common.Max(innerDegree, inner.Degree())
+		}
+	}
+	return f.Outer.Degree() * innerDegree
+}
+
+// EvalBatch evaluates the inner gates then the outer one
+func (f *FusedGate) EvalBatch(res []fr.Element, xs ...[]fr.Element) {
+	ys := make([][]fr.Element, len(f.Inners))
+	for i, inner := range f.Inners {
+		if inner == nil {
+			ys[i] = xs[f.Inputs[i][0]]
+			continue
+		}
+		args := make([][]fr.Element, len(f.Inputs[i]))
+		for k, x := range f.Inputs[i] {
+			args[k] = xs[x]
+		}
+		ys[i] = make([]fr.Element, len(res))
+		inner.EvalBatch(ys[i], args...)
+	}
+	f.Outer.EvalBatch(res, ys...)
+}
+
+// Eval evaluates the inner gates then the outer one
+func (f *FusedGate) Eval(res *fr.Element, xs ...*fr.Element) {
+	ys := make([]*fr.Element, len(f.Inners))
+	for i, inner := range f.Inners {
+		if inner == nil {
+			ys[i] = xs[f.Inputs[i][0]]
+			continue
+		}
+		args := make([]*fr.Element, len(f.Inputs[i]))
+		for k, x := range f.Inputs[i] {
+			args[k] = xs[x]
+		}
+		ys[i] = new(fr.Element)
+		inner.Eval(ys[i], args...)
+	}
+	f.Outer.Eval(res, ys...)
+}
+
+// GnarkEval evaluates the inner gates then the outer one in a circuit
+func (f *FusedGate) GnarkEval(cs frontend.API, xs ...frontend.Variable) frontend.Variable {
+	ys := make([]frontend.Variable, len(f.Inners))
+	for i, inner := range f.Inners {
+		if inner == nil {
+			ys[i] = xs[f.Inputs[i][0]]
+			continue
+		}
+		args := make([]frontend.Variable, len(f.Inputs[i]))
+		for k, x := range f.Inputs[i] {
+			args[k] = xs[x]
+		}
+		ys[i] = inner.GnarkEval(cs, args...)
+	}
+	return f.Outer.GnarkEval(cs, ys...)
+}
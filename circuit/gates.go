@@ -5,7 +5,10 @@ import (
 	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
 )
 
-// Gate assumes the gate can only have 2 inputs
+// Gate is a low-degree polynomial computing a layer from its input layers
+//
+// Gates may additionally implement `Arity() int` returning their number of inputs.
+// In that case, `Circuit.Validate` checks it against the inputs of the layers using them.
 type Gate interface {
 	// ID returns an ID that is unique for the gate
 	ID() string
@@ -19,3 +22,16 @@ type Gate interface {
 	// Degree returns the degrees of the gate relatively to HPrime
 	Degree() (degHPrime int)
 }
+
+// SumcheckGate is an optional interface of the gates : it lets them compute the round polynomials
+// of the sumcheck prover themselves, typically to exploit their structure. `sumcheck.Prove` uses
+// it in place of the generic evaluations through `EvalBatch` when the gate implements it.
+type SumcheckGate interface {
+	Gate
+	// PartialEvals returns the evaluations at t = 0, 1, ..., Degree() + 1 of
+	//
+	// 		\sum_{x} eq(t, x) * Gate(X_0(t, x), X_1(t, x), ...)
+	//
+	// where `eq(t, x) = (1 - t) * eq0[x] + t * eq1[x]` and `X_k(t, x) = (1 - t) * x0[k][x] + t * x1[k][x]`
+	PartialEvals(eq0, eq1 []fr.Element, x0, x1 [][]fr.Element) []fr.Element
+}
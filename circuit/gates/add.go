@@ -1,43 +1,93 @@
 package gates
 
 import (
+	"fmt"
+
 	"github.com/AlexandreBelling/gnark/frontend"
 	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
 )
 
-// AddGate performs an addition
+// AddGate performs an addition of its two inputs
 type AddGate struct{}
 
 // ID returns the gate ID
 func (a AddGate) ID() string { return "AddGate" }
 
+// Arity returns the number of inputs of the gate
+func (a AddGate) Arity() int { return 2 }
+
+// EvalBatch returns vL + vR for a range of inputs
+func (a AddGate) EvalBatch(res []fr.Element, xs ...[]fr.Element) {
+	ls := xs[0]
+	rs := xs[1]
+	for i := range res {
+		res[i].Add(&ls[i], &rs[i])
+	}
+}
+
 // Eval return the result vL + vR
-func (a AddGate) Eval(res, vL, vR *fr.Element) {
-	res.Add(vL, vR)
+func (a AddGate) Eval(res *fr.Element, xs ...*fr.Element) {
+	res.Add(xs[0], xs[1])
 }
 
 // GnarkEval compute the gate on a gnark circuit
-func (a AddGate) GnarkEval(cs frontend.API, vL, vR frontend.Variable) frontend.Variable {
-	// Unoptimized, but unlikely to cause any significant performance loss
-	return cs.Add(vL, vR)
+func (a AddGate) GnarkEval(cs frontend.API, xs ...frontend.Variable) frontend.Variable {
+	return cs.Add(xs[0], xs[1])
+}
+
+// Degree returns the degree of the gate
+func (a AddGate) Degree() (degHPrime int) {
+	return 1
+}
+
+// SumGate returns the sum of an arbitrary number of inputs
+type SumGate struct {
+	NbInputs int
+}
+
+// NewSumGate returns a gate summing `nbInputs` inputs
+func NewSumGate(nbInputs int) *SumGate {
+	if nbInputs < 1 {
+		panic(fmt.Sprintf("a sum gate needs at least one input, got %v", nbInputs))
+	}
+	return &SumGate{NbInputs: nbInputs}
+}
+
+// ID returns the ID of the gate, including its number of inputs
+func (s *SumGate) ID() string { return fmt.Sprintf("SumGate-%v", s.NbInputs) }
+
+// Arity returns the number of inputs of the gate
+func (s *SumGate) Arity() int { return s.NbInputs }
+
+// EvalBatch returns x0 + x1 + ... for a range of inputs
+func (s *SumGate) EvalBatch(res []fr.Element, xs ...[]fr.Element) {
+	copy(res, xs[0])
+	for _, x := range xs[1:] {
+		for i := range res {
+			res[i].Add(&res[i], &x[i])
+		}
+	}
 }
 
-// EvalManyVR performs an element-wise addition of many vRs values by one vL value
-// res must be initialized with the same size as vRs
-func (a AddGate) EvalManyVR(res []fr.Element, vL *fr.Element, vRs []fr.Element) {
-	for i, vR := range vRs {
-		res[i].Add(vL, &vR)
+// Eval returns x0 + x1 + ...
+func (s *SumGate) Eval(res *fr.Element, xs ...*fr.Element) {
+	var tmp fr.Element
+	tmp.Set(xs[0])
+	for _, x := range xs[1:] {
+		tmp.Add(&tmp, x)
 	}
+	res.Set(&tmp)
 }
 
-// EvalManyVL performs an element-wise addition of many vLs values by one vR value
-func (a AddGate) EvalManyVL(res []fr.Element, vLs []fr.Element, vR *fr.Element) {
-	for i, vL := range vLs {
-		res[i].Add(&vL, vR)
+// GnarkEval performs the sum on gnark variables
+func (s *SumGate) GnarkEval(cs frontend.API, xs ...frontend.Variable) frontend.Variable {
+	if len(xs) == 1 {
+		return xs[0]
 	}
+	return cs.Add(xs[0], xs[1], xs[2:]...)
 }
 
-// Degrees returns the degrees of the gate on hL, hR and hPrime
-func (a AddGate) Degrees() (degHL, degHR, degHPrime int) {
-	return 1, 1, 1
+// Degree returns the degree of the gate
+func (s *SumGate) Degree() (degHPrime int) {
+	return 1
 }
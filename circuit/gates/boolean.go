@@ -0,0 +1,149 @@
+package gates
+
+import (
+	"github.com/AlexandreBelling/gnark/frontend"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+)
+
+// The boolean gates are the multilinear extensions of the boolean operations : they return the
+// expected bit when their inputs are bits. They do not check that the inputs are bits, this is up
+// to the circuit, for instance with an assertion layer computing `x * (x - 1)`.
+
+// SelectGate returns vA if the selector is one and vB if it is zero, i.e. s * vA + (1 - s) * vB.
+// The inputs are in the order (s, vA, vB).
+type SelectGate struct{}
+
+// ID returns the gate ID
+func (s SelectGate) ID() string { return "SelectGate" }
+
+// Arity returns the number of inputs of the gate
+func (s SelectGate) Arity() int { return 3 }
+
+// EvalBatch returns vB + s * (vA - vB) for a range of inputs
+func (s SelectGate) EvalBatch(res []fr.Element, xs ...[]fr.Element) {
+	ss, as, bs := xs[0], xs[1], xs[2]
+	var tmp fr.Element
+	for i := range res {
+		tmp.Sub(&as[i], &bs[i])
+		tmp.Mul(&tmp, &ss[i])
+		res[i].Add(&tmp, &bs[i])
+	}
+}
+
+// Eval returns vB + s * (vA - vB)
+func (s SelectGate) Eval(res *fr.Element, xs ...*fr.Element) {
+	var tmp fr.Element
+	tmp.Sub(xs[1], xs[2])
+	tmp.Mul(&tmp, xs[0])
+	res.Add(&tmp, xs[2])
+}
+
+// GnarkEval performs the selection on gnark variables with a single multiplication
+func (s SelectGate) GnarkEval(cs frontend.API, xs ...frontend.Variable) frontend.Variable {
+	return cs.Add(xs[2], cs.Mul(xs[0], cs.Sub(xs[1], xs[2])))
+}
+
+// Degree returns the degree of the gate
+func (s SelectGate) Degree() (degHPrime int) {
+	return 2
+}
+
+// XorGate returns vL xor vR over the field, i.e. vL + vR - 2 * vL * vR
+type XorGate struct{}
+
+// ID returns the gate ID
+func (x XorGate) ID() string { return "XorGate" }
+
+// Arity returns the number of inputs of the gate
+func (x XorGate) Arity() int { return 2 }
+
+// EvalBatch returns vL + vR - 2 * vL * vR for a range of inputs
+func (x XorGate) EvalBatch(res []fr.Element, xs ...[]fr.Element) {
+	for i := range res {
+		x.Eval(&res[i], &xs[0][i], &xs[1][i])
+	}
+}
+
+// Eval returns vL + vR - 2 * vL * vR
+func (x XorGate) Eval(res *fr.Element, xs ...*fr.Element) {
+	var prod, sum fr.Element
+	prod.Mul(xs[0], xs[1])
+	prod.Double(&prod)
+	sum.Add(xs[0], xs[1])
+	res.Sub(&sum, &prod)
+}
+
+// GnarkEval performs the xor on gnark variables
+func (x XorGate) GnarkEval(cs frontend.API, xs ...frontend.Variable) frontend.Variable {
+	prod := cs.Mul(xs[0], xs[1])
+	return cs.Sub(cs.Add(xs[0], xs[1]), cs.Mul(prod, 2))
+}
+
+// Degree returns the degree of the gate
+func (x XorGate) Degree() (degHPrime int) {
+	return 2
+}
+
+// AndGate returns vL and vR over the field, i.e. vL * vR
+type AndGate struct{}
+
+// ID returns the gate ID
+func (a AndGate) ID() string { return "AndGate" }
+
+// Arity returns the number of inputs of the gate
+func (a AndGate) Arity() int { return 2 }
+
+// EvalBatch returns vL * vR for a range of inputs
+func (a AndGate) EvalBatch(res []fr.Element, xs ...[]fr.Element) {
+	MulGate{}.EvalBatch(res, xs...)
+}
+
+// Eval returns vL * vR
+func (a AndGate) Eval(res *fr.Element, xs ...*fr.Element) {
+	res.Mul(xs[0], xs[1])
+}
+
+// GnarkEval performs the and on gnark variables
+func (a AndGate) GnarkEval(cs frontend.API, xs ...frontend.Variable) frontend.Variable {
+	return cs.Mul(xs[0], xs[1])
+}
+
+// Degree returns the degree of the gate
+func (a AndGate) Degree() (degHPrime int) {
+	return 2
+}
+
+// NotGate returns the negation of its input over the field, i.e. 1 - x
+type NotGate struct{}
+
+// ID returns the gate ID
+func (n NotGate) ID() string { return "NotGate" }
+
+// Arity returns the number of inputs of the gate
+func (n NotGate) Arity() int { return 1 }
+
+// EvalBatch returns 1 - x for a range of inputs
+func (n NotGate) EvalBatch(res []fr.Element, xs ...[]fr.Element) {
+	var one fr.Element
+	one.SetOne()
+	for i := range res {
+		res[i].Sub(&one, &xs[0][i])
+	}
+}
+
+// Eval returns 1 - x
+func (n NotGate) Eval(res *fr.Element, xs ...*fr.Element) {
+	var one fr.Element
+	one.SetOne()
+	res.Sub(&one, xs[0])
+}
+
+// GnarkEval performs the negation on gnark variables
+func (n NotGate) GnarkEval(cs frontend.API, xs ...frontend.Variable) frontend.Variable {
+	return cs.Sub(1, xs[0])
+}
+
+// Degree returns the degree of the gate
+func (n NotGate) Degree() (degHPrime int) {
+	return 1
+}
@@ -0,0 +1,184 @@
+package gates
+
+import (
+	"github.com/AlexandreBelling/gnark/backend"
+	"github.com/AlexandreBelling/gnark/frontend"
+	"github.com/AlexandreBelling/gnark/test"
+	"github.com/consensys/gkr-mimc/circuit"
+	"github.com/consensys/gkr-mimc/common"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+)
+
+// TestingT is the subset of `testing.TB` used by `CheckGate`, so that the harness can be run against a fake
+type TestingT interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+	Fatalf(format string, args ...interface{})
+}
+
+// checkGateSize is the number of random instances on which the gates are tested
+const checkGateSize int = 10
+
+// CheckGate runs the conformance tests every gate should pass. The gate must implement `Arity() int`.
+//   - `EvalBatch` and `Eval` agree on random inputs
+//   - `GnarkEval` agrees with `Eval` when run by the gnark test engine
+//   - `Degree()` is the degree of the gate : it is checked by evaluating the gate along random lines
+//   - `PartialEvals` agrees with `Eval`, if the gate implements `circuit.SumcheckGate`
+func CheckGate(t TestingT, gate circuit.Gate) {
+	t.Helper()
+
+	g, ok := gate.(interface{ Arity() int })
+	if !ok {
+		t.Fatalf("gate %v does not implement `Arity() int`", gate.ID())
+	}
+	arity := g.Arity()
+
+	xs := make([][]fr.Element, arity)
+	for k := range xs {
+		xs[k] = common.RandomFrArray(checkGateSize)
+	}
+
+	expected := make([]fr.Element, checkGateSize)
+	gate.EvalBatch(expected, xs...)
+
+	for z := range expected {
+		inputs := make([]*fr.Element, arity)
+		for k := range inputs {
+			inputs[k] = &xs[k][z]
+		}
+		var res fr.Element
+		gate.Eval(&res, inputs...)
+		if res != expected[z] {
+			t.Errorf("gate %v : EvalBatch and Eval disagree on instance %v", gate.ID(), z)
+		}
+	}
+
+	checkGnarkEval(t, gate, xs, expected)
+	checkDegree(t, gate, arity)
+
+	if g, ok := gate.(circuit.SumcheckGate); ok {
+		checkPartialEvals(t, g, arity)
+	}
+}
+
+// gnarkEvalTestCircuit asserts that `GnarkEval` on the inputs returns the output
+type gnarkEvalTestCircuit struct {
+	Inputs []frontend.Variable
+	Output frontend.Variable
+	gate   circuit.Gate
+}
+
+// Define declares the constraints of the gate
+func (c *gnarkEvalTestCircuit) Define(cs frontend.API) error {
+	cs.AssertIsEqual(c.gate.GnarkEval(cs, c.Inputs...), c.Output)
+	return nil
+}
+
+// checkGnarkEval checks `GnarkEval` on the first instances of `xs`, whose evaluations are `expected`
+func checkGnarkEval(t TestingT, gate circuit.Gate, xs [][]fr.Element, expected []fr.Element) {
+	t.Helper()
+
+	definition := gnarkEvalTestCircuit{Inputs: make([]frontend.Variable, len(xs)), gate: gate}
+
+	// The test engine is slow-ish : a few instances are enough
+	for z := 0; z < 3; z++ {
+		witness := gnarkEvalTestCircuit{Inputs: make([]frontend.Variable, len(xs)), Output: expected[z]}
+		for k := range xs {
+			witness.Inputs[k] = xs[k][z]
+		}
+		if err := test.IsSolved(&definition, &witness, ecc.BN254, backend.GROTH16); err != nil {
+			t.Errorf("gate %v : GnarkEval disagrees with EvalBatch on instance %v : %v", gate.ID(), z, err)
+		}
+
+		var wrong fr.Element
+		wrong.SetOne()
+		witness.Output = wrong.Add(&wrong, &expected[z])
+		if err := test.IsSolved(&definition, &witness, ecc.BN254, backend.GROTH16); err == nil {
+			t.Errorf("gate %v : GnarkEval accepts a wrong output on instance %v", gate.ID(), z)
+		}
+	}
+}
+
+// checkDegree checks that the restriction of the gate to a random line `a + s * b` is a
+// univariate polynomial of degree `gate.Degree()`. It evaluates it on `s = 0, 1, ..., d + 1`
+// and computes the finite differences : the difference of order `d + 1` is zero if the
+// degree is at most `d`, and the one of order `d` is non-zero if the degree is at least `d`.
+func checkDegree(t TestingT, gate circuit.Gate, arity int) {
+	t.Helper()
+
+	d := gate.Degree()
+	if d < 0 {
+		t.Fatalf("gate %v has a negative degree %v", gate.ID(), d)
+	}
+
+	a := common.RandomFrArray(arity)
+	b := common.RandomFrArray(arity)
+
+	diffs := make([]fr.Element, d+2)
+	for s := range diffs {
+		var scalar fr.Element
+		scalar.SetUint64(uint64(s))
+		inputs := make([]*fr.Element, arity)
+		for k := range inputs {
+			inputs[k] = new(fr.Element).Mul(&b[k], &scalar)
+			inputs[k].Add(inputs[k], &a[k])
+		}
+		gate.Eval(&diffs[s], inputs...)
+	}
+
+	// After the i-th pass, diffs[0] holds the difference of order i
+	for i := 1; i <= d+1; i++ {
+		if i == d+1 && diffs[0].IsZero() {
+			t.Errorf("gate %v : its degree is lower than %v", gate.ID(), d)
+		}
+		for s := 0; s < len(diffs)-i; s++ {
+			diffs[s].Sub(&diffs[s+1], &diffs[s])
+		}
+	}
+
+	if !diffs[0].IsZero() {
+		t.Errorf("gate %v : its degree is higher than %v", gate.ID(), d)
+	}
+}
+
+// checkPartialEvals compares the round polynomial returned by the gate with the one obtained by
+// evaluating the gate on each instance
+func checkPartialEvals(t TestingT, gate circuit.SumcheckGate, arity int) {
+	t.Helper()
+
+	eq0, eq1 := common.RandomFrArray(checkGateSize), common.RandomFrArray(checkGateSize)
+	x0, x1 := make([][]fr.Element, arity), make([][]fr.Element, arity)
+	for k := range x0 {
+		x0[k], x1[k] = common.RandomFrArray(checkGateSize), common.RandomFrArray(checkGateSize)
+	}
+
+	evals := gate.PartialEvals(eq0, eq1, x0, x1)
+	if len(evals) != gate.Degree()+2 {
+		t.Fatalf("gate %v : PartialEvals returned %v evaluations, expected %v", gate.ID(), len(evals), gate.Degree()+2)
+	}
+
+	// Interpolates linearly between the values at t = 0 and t = 1
+	at := func(v0, v1, s *fr.Element) *fr.Element {
+		res := new(fr.Element).Sub(v1, v0)
+		res.Mul(res, s)
+		return res.Add(res, v0)
+	}
+
+	for s := range evals {
+		var scalar, expected, tmp fr.Element
+		scalar.SetUint64(uint64(s))
+		for z := 0; z < checkGateSize; z++ {
+			inputs := make([]*fr.Element, arity)
+			for k := range inputs {
+				inputs[k] = at(&x0[k][z], &x1[k][z], &scalar)
+			}
+			gate.Eval(&tmp, inputs...)
+			tmp.Mul(&tmp, at(&eq0[z], &eq1[z], &scalar))
+			expected.Add(&expected, &tmp)
+		}
+		if evals[s] != expected {
+			t.Errorf("gate %v : PartialEvals is wrong at t = %v", gate.ID(), s)
+		}
+	}
+}
@@ -21,6 +21,9 @@ func NewCipherGate(ark fr.Element) *CipherGate {
 // ID returns the id of the cipher gate and print the ark as well
 func (c *CipherGate) ID() string { return fmt.Sprintf("CipherGate-%v", c.Ark.String()) }
 
+// Arity returns the number of inputs of the gate
+func (c *CipherGate) Arity() int { return 2 }
+
 // Eval returns (vR + c + vL)^7, on the range of output
 func (c *CipherGate) EvalBatch(res []fr.Element, xs ...[]fr.Element) {
 
@@ -68,3 +71,18 @@ func (c *CipherGate) GnarkEval(cs frontend.API, xs ...frontend.Variable) fronten
 func (c *CipherGate) Degree() (degHPrime int) {
 	return 7
 }
+
+// PartialEvals computes the round polynomials of the sumcheck, see `circuit.SumcheckGate`.
+// It uses that `vL + vR + c` is affine, see `powAccumulator`.
+func (c *CipherGate) PartialEvals(eq0, eq1 []fr.Element, x0, x1 [][]fr.Element) []fr.Element {
+	acc := newPowAccumulator(7)
+	var s0, s1 fr.Element
+	for i := range eq0 {
+		s0.Add(&x0[0][i], &x0[1][i])
+		s0.Add(&s0, &c.Ark)
+		s1.Add(&x1[0][i], &x1[1][i])
+		s1.Add(&s1, &c.Ark)
+		acc.add(&eq0[i], &eq1[i], &s0, &s1)
+	}
+	return acc.evals()
+}
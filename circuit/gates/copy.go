@@ -11,6 +11,9 @@ type IdentityGate struct{}
 // ID returns "CopyGate" as an ID for CopyGate
 func (c IdentityGate) ID() string { return "CopyGate" }
 
+// Arity returns the number of inputs of the gate
+func (c IdentityGate) Arity() int { return 1 }
+
 // Eval returns for a range of inputs
 func (c IdentityGate) EvalBatch(res []fr.Element, xs ...[]fr.Element) {
 	copy(res, xs[0])
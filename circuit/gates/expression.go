@@ -0,0 +1,467 @@
+package gates
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/AlexandreBelling/gnark/frontend"
+	"github.com/consensys/gkr-mimc/circuit"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+)
+
+// exprOp is the operation performed by a node of an expression
+type exprOp int
+
+const (
+	opInput exprOp = iota
+	opConstant
+	opSum
+	opSub
+	opProd
+	opPow
+)
+
+// Expression describes a polynomial over the inputs of a gate and constants.
+// It is built using `Input`, `Constant`, `Sum`, `Sub`, `Prod` and `Pow`.
+// Expressions are immutable and can be shared between several gates.
+type Expression struct {
+	op       exprOp
+	input    int        // only for opInput
+	constant fr.Element // only for opConstant
+	exponent int        // only for opPow
+	args     []*Expression
+}
+
+// Input returns the expression of the `i`-th input of the gate
+func Input(i int) *Expression {
+	if i < 0 {
+		panic(fmt.Sprintf("negative input index %v", i))
+	}
+	return &Expression{op: opInput, input: i}
+}
+
+// Constant returns a constant expression
+func Constant(c fr.Element) *Expression {
+	return &Expression{op: opConstant, constant: c}
+}
+
+// Sum returns the expression args[0] + args[1] + ...
+func Sum(args ...*Expression) *Expression {
+	if len(args) < 1 {
+		panic("sum of zero expressions")
+	}
+	return newNode(opSum, 0, args...)
+}
+
+// Sub returns the expression a - b
+func Sub(a, b *Expression) *Expression {
+	return newNode(opSub, 0, a, b)
+}
+
+// Prod returns the expression args[0] * args[1] * ...
+func Prod(args ...*Expression) *Expression {
+	if len(args) < 1 {
+		panic("product of zero expressions")
+	}
+	return newNode(opProd, 0, args...)
+}
+
+// Pow returns the expression x^exponent
+func Pow(x *Expression, exponent int) *Expression {
+	if exponent < 1 {
+		panic(fmt.Sprintf("exponent must be positive, got %v", exponent))
+	}
+	return newNode(opPow, exponent, x)
+}
+
+// newNode returns a node of the expression tree. If all the arguments are
+// constants, the node is directly replaced by its value.
+func newNode(op exprOp, exponent int, args ...*Expression) *Expression {
+	res := &Expression{op: op, exponent: exponent, args: args}
+	for _, arg := range args {
+		if arg.op != opConstant {
+			return res
+		}
+	}
+	var c fr.Element
+	res.eval(&c, nil)
+	return Constant(c)
+}
+
+// Degree returns the total degree of the expression in its inputs
+func (e *Expression) Degree() int {
+	switch e.op {
+	case opInput:
+		return 1
+	case opConstant:
+		return 0
+	case opSum, opSub:
+		res := 0
+		for _, arg := range e.args {
+			if d := arg.Degree(); d > res {
+				res = d
+			}
+		}
+		return res
+	case opProd:
+		res := 0
+		for _, arg := range e.args {
+			res += arg.Degree()
+		}
+		return res
+	case opPow:
+		return e.exponent * e.args[0].Degree()
+	}
+	panic("unknown expression operation")
+}
+
+// NbInputs returns the number of inputs the expression reads: that is the
+// largest input index + 1
+func (e *Expression) NbInputs() int {
+	if e.op == opInput {
+		return e.input + 1
+	}
+	res := 0
+	for _, arg := range e.args {
+		if n := arg.NbInputs(); n > res {
+			res = n
+		}
+	}
+	return res
+}
+
+// String returns a canonical representation of the expression, that can be parsed
+// back by `ParseExpression`. For instance `add(x0,pow(add(x1,5),7))`
+func (e *Expression) String() string {
+	switch e.op {
+	case opInput:
+		return fmt.Sprintf("x%v", e.input)
+	case opConstant:
+		return e.constant.String()
+	case opPow:
+		return fmt.Sprintf("pow(%v,%v)", e.args[0].String(), e.exponent)
+	}
+
+	names := map[exprOp]string{opSum: "add", opSub: "sub", opProd: "mul"}
+	args := make([]string, len(e.args))
+	for i, arg := range e.args {
+		args[i] = arg.String()
+	}
+	return fmt.Sprintf("%v(%v)", names[e.op], strings.Join(args, ","))
+}
+
+// eval evaluates the expression on a single set of inputs
+func (e *Expression) eval(res *fr.Element, xs []*fr.Element) {
+	switch e.op {
+	case opInput:
+		res.Set(xs[e.input])
+	case opConstant:
+		res.Set(&e.constant)
+	case opSum, opSub, opProd:
+		var tmp fr.Element
+		e.args[0].eval(res, xs)
+		for _, arg := range e.args[1:] {
+			arg.eval(&tmp, xs)
+			switch e.op {
+			case opSum:
+				res.Add(res, &tmp)
+			case opSub:
+				res.Sub(res, &tmp)
+			case opProd:
+				res.Mul(res, &tmp)
+			}
+		}
+	case opPow:
+		var x fr.Element
+		e.args[0].eval(&x, xs)
+		powInplace(res, &x, e.exponent)
+	}
+}
+
+// evalBatch evaluates the expression on a range of inputs and writes the result in res.
+// The inputs are left untouched.
+func (e *Expression) evalBatch(res []fr.Element, xs [][]fr.Element) {
+	switch e.op {
+	case opInput:
+		copy(res, xs[e.input])
+		return
+	case opConstant:
+		for i := range res {
+			res[i] = e.constant
+		}
+		return
+	case opPow:
+		e.args[0].evalBatch(res, xs)
+		var x fr.Element
+		for i := range res {
+			x = res[i]
+			powInplace(&res[i], &x, e.exponent)
+		}
+		return
+	}
+
+	e.args[0].evalBatch(res, xs)
+
+	// Buffer for the evaluations of the other arguments. Inputs and constants
+	// are read directly without going through the buffer.
+	var tmp []fr.Element
+
+	for _, arg := range e.args[1:] {
+		var vals []fr.Element
+		switch arg.op {
+		case opConstant:
+			e.applyScalar(res, &arg.constant)
+			continue
+		case opInput:
+			vals = xs[arg.input]
+		default:
+			if tmp == nil {
+				tmp = make([]fr.Element, len(res))
+			}
+			arg.evalBatch(tmp, xs)
+			vals = tmp
+		}
+
+		for i := range res {
+			switch e.op {
+			case opSum:
+				res[i].Add(&res[i], &vals[i])
+			case opSub:
+				res[i].Sub(&res[i], &vals[i])
+			case opProd:
+				res[i].Mul(&res[i], &vals[i])
+			}
+		}
+	}
+}
+
+// applyScalar combines all entries of res with a constant using the operation of the node
+func (e *Expression) applyScalar(res []fr.Element, c *fr.Element) {
+	for i := range res {
+		switch e.op {
+		case opSum:
+			res[i].Add(&res[i], c)
+		case opSub:
+			res[i].Sub(&res[i], c)
+		case opProd:
+			res[i].Mul(&res[i], c)
+		}
+	}
+}
+
+// gnarkEval evaluates the expression on gnark variables
+func (e *Expression) gnarkEval(cs frontend.API, xs []frontend.Variable) frontend.Variable {
+	switch e.op {
+	case opInput:
+		return xs[e.input]
+	case opConstant:
+		return frontend.Variable(e.constant)
+	case opPow:
+		return gnarkPow(cs, e.args[0].gnarkEval(cs, xs), e.exponent)
+	}
+
+	args := make([]frontend.Variable, len(e.args))
+	for i, arg := range e.args {
+		args[i] = arg.gnarkEval(cs, xs)
+	}
+
+	if len(args) == 1 {
+		return args[0]
+	}
+
+	switch e.op {
+	case opSum:
+		return cs.Add(args[0], args[1], args[2:]...)
+	case opSub:
+		return cs.Sub(args[0], args[1], args[2:]...)
+	default:
+		return cs.Mul(args[0], args[1], args[2:]...)
+	}
+}
+
+// powInplace sets res = x^exponent using a square and multiply
+// res and x must not alias
+func powInplace(res, x *fr.Element, exponent int) {
+	res.SetOne()
+	for bit := highestBit(exponent); bit >= 0; bit-- {
+		res.Square(res)
+		if (exponent>>bit)&1 == 1 {
+			res.Mul(res, x)
+		}
+	}
+}
+
+// gnarkPow returns x^exponent using a square and multiply
+func gnarkPow(cs frontend.API, x frontend.Variable, exponent int) frontend.Variable {
+	res := x
+	for bit := highestBit(exponent) - 1; bit >= 0; bit-- {
+		res = cs.Mul(res, res)
+		if (exponent>>bit)&1 == 1 {
+			res = cs.Mul(res, x)
+		}
+	}
+	return res
+}
+
+// highestBit returns the position of the highest set bit of a positive integer
+func highestBit(x int) int {
+	res := -1
+	for ; x > 0; x >>= 1 {
+		res++
+	}
+	return res
+}
+
+// ParseExpression parses an expression printed by `Expression.String()`
+func ParseExpression(s string) (*Expression, error) {
+	p := exprParser{s: s}
+	e, err := p.parse()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos != len(s) {
+		return nil, fmt.Errorf("unexpected trailing characters %q in expression %q", s[p.pos:], s)
+	}
+	return e, nil
+}
+
+// exprParser is a recursive descent parser for expressions
+type exprParser struct {
+	s   string
+	pos int
+}
+
+func (p *exprParser) parse() (*Expression, error) {
+	// Reads the leading token : until the next delimiter
+	start := p.pos
+	for p.pos < len(p.s) && !strings.ContainsRune("(),", rune(p.s[p.pos])) {
+		p.pos++
+	}
+	token := p.s[start:p.pos]
+
+	if len(token) == 0 {
+		return nil, fmt.Errorf("empty token at position %v in %q", start, p.s)
+	}
+
+	// Function call
+	if p.pos < len(p.s) && p.s[p.pos] == '(' {
+		p.pos++
+		var args []*Expression
+		for {
+			// The exponent of pow is not an expression
+			if token == "pow" && len(args) == 1 {
+				break
+			}
+			arg, err := p.parse()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+			if p.pos >= len(p.s) || p.s[p.pos] != ',' {
+				break
+			}
+			p.pos++
+		}
+
+		var exponent int
+		if token == "pow" {
+			expStart := p.pos
+			for p.pos < len(p.s) && p.s[p.pos] != ')' {
+				p.pos++
+			}
+			var err error
+			exponent, err = strconv.Atoi(p.s[expStart:p.pos])
+			if err != nil || exponent < 1 {
+				return nil, fmt.Errorf("invalid exponent %q in %q", p.s[expStart:p.pos], p.s)
+			}
+		}
+
+		if p.pos >= len(p.s) || p.s[p.pos] != ')' {
+			return nil, fmt.Errorf("expected `)` at position %v in %q", p.pos, p.s)
+		}
+		p.pos++
+
+		switch token {
+		case "add":
+			return Sum(args...), nil
+		case "sub":
+			if len(args) != 2 {
+				return nil, fmt.Errorf("sub takes 2 arguments, got %v in %q", len(args), p.s)
+			}
+			return Sub(args[0], args[1]), nil
+		case "mul":
+			return Prod(args...), nil
+		case "pow":
+			return Pow(args[0], exponent), nil
+		}
+		return nil, fmt.Errorf("unknown operation %q in %q", token, p.s)
+	}
+
+	// Input
+	if token[0] == 'x' {
+		i, err := strconv.Atoi(token[1:])
+		if err != nil || i < 0 {
+			return nil, fmt.Errorf("invalid input %q in %q", token, p.s)
+		}
+		return Input(i), nil
+	}
+
+	// Constant
+	c, err := parseElement(token)
+	if err != nil {
+		return nil, err
+	}
+	return Constant(c), nil
+}
+
+// ExpressionGate is a gate whose evaluations and degree are all derived
+// from a single polynomial expression
+type ExpressionGate struct {
+	expr     *Expression
+	degree   int
+	nbInputs int
+}
+
+// FromExpression returns a gate computing the expression `e`
+func FromExpression(e *Expression) *ExpressionGate {
+	return &ExpressionGate{expr: e, degree: e.Degree(), nbInputs: e.NbInputs()}
+}
+
+// ID returns "Expr-" followed by the canonical representation of the expression
+func (g *ExpressionGate) ID() string { return "Expr-" + g.expr.String() }
+
+// Expression returns the expression computed by the gate
+func (g *ExpressionGate) Expression() *Expression { return g.expr }
+
+// Arity returns the number of inputs of the gate
+func (g *ExpressionGate) Arity() int { return g.nbInputs }
+
+// EvalBatch evaluates the expression for a range of inputs
+func (g *ExpressionGate) EvalBatch(res []fr.Element, xs ...[]fr.Element) {
+	g.expr.evalBatch(res, xs)
+}
+
+// Eval evaluates the expression on a single set of inputs
+func (g *ExpressionGate) Eval(res *fr.Element, xs ...*fr.Element) {
+	g.expr.eval(res, xs)
+}
+
+// GnarkEval evaluates the expression on gnark variables
+func (g *ExpressionGate) GnarkEval(cs frontend.API, xs ...frontend.Variable) frontend.Variable {
+	return g.expr.gnarkEval(cs, xs)
+}
+
+// Degree returns the total degree of the expression
+func (g *ExpressionGate) Degree() (degHPrime int) {
+	return g.degree
+}
+
+// newExpressionGateFromParams rebuilds an expression gate from its canonical representation
+func newExpressionGateFromParams(params string) (circuit.Gate, error) {
+	e, err := ParseExpression(params)
+	if err != nil {
+		return nil, err
+	}
+	return FromExpression(e), nil
+}
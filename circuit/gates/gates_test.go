@@ -1,6 +1,7 @@
 package gates
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/consensys/gkr-mimc/circuit"
@@ -9,32 +10,231 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
-func genericTest(t *testing.T, gate circuit.Gate) {
+func TestGates(t *testing.T) {
+	var minusOne fr.Element
+	minusOne.SetOne()
+	minusOne.Neg(&minusOne)
 
-	size := 10
+	gates := []circuit.Gate{
+		IdentityGate{},
+		NewCipherGate(fr.NewElement(25)),
+		AddGate{},
+		MulGate{},
+		NewSumGate(2),
+		NewProductGate(2),
+		NewSumGate(3),
+		NewProductGate(4),
+		NewLinearCombinationGate([]fr.Element{fr.NewElement(2), minusOne, fr.NewElement(7)}, fr.NewElement(3)),
+		FromExpression(Sub(
+			Prod(Input(0), Pow(Input(2), 3), Constant(minusOne)),
+			Sum(Input(1), Constant(fr.NewElement(3))),
+		)),
+	}
+
+	for _, gate := range gates {
+		CheckGate(t, gate)
+	}
+}
+
+func TestNaryGates(t *testing.T) {
+	// The conformance of the n-ary gates is checked by `TestGates`, these are their values
+	assert.Equal(t, 3, NewProductGate(3).Degree())
+
+	// 2 * 1 - 2 + 7 * 3 + 3 = 24
+	var minusOne, res fr.Element
+	minusOne.SetOne()
+	minusOne.Neg(&minusOne)
+	lincomb := NewLinearCombinationGate([]fr.Element{fr.NewElement(2), minusOne, fr.NewElement(7)}, fr.NewElement(3))
+	one, two, three := fr.NewElement(1), fr.NewElement(2), fr.NewElement(3)
+	lincomb.Eval(&res, &one, &two, &three)
+	assert.Equal(t, fr.NewElement(24), res)
+}
+
+// recordingT is a fake `TestingT` recording the failures. Like `testing.T`, `Fatalf` stops the
+// check, which `run` recovers from.
+type recordingT struct {
+	errors, fatals []string
+}
+
+type fatalStop struct{}
+
+func (r *recordingT) Helper() {}
+
+func (r *recordingT) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *recordingT) Fatalf(format string, args ...interface{}) {
+	r.fatals = append(r.fatals, fmt.Sprintf(format, args...))
+	panic(fatalStop{})
+}
+
+func (r *recordingT) run(check func(t TestingT)) {
+	defer func() {
+		if p := recover(); p != nil {
+			if _, ok := p.(fatalStop); !ok {
+				panic(p)
+			}
+		}
+	}()
+	check(r)
+}
+
+// A gate whose declared degree is wrong is caught by the harness
+type wrongDegreeGate struct{ MulGate }
+
+func (wrongDegreeGate) Degree() int { return 1 }
+
+type negativeDegreeGate struct{ MulGate }
+
+func (negativeDegreeGate) Degree() int { return -1 }
+
+func TestCheckGateDegree(t *testing.T) {
+	inner := &recordingT{}
+	inner.run(func(t TestingT) { checkDegree(t, wrongDegreeGate{}, 2) })
+	assert.NotEmpty(t, inner.errors, "the degree of the gate is underestimated")
+	assert.Empty(t, inner.fatals)
+
+	inner = &recordingT{}
+	inner.run(func(t TestingT) { checkDegree(t, NewProductGate(3), 3) })
+	assert.Empty(t, inner.errors)
+	assert.Empty(t, inner.fatals)
+
+	inner = &recordingT{}
+	inner.run(func(t TestingT) { checkDegree(t, negativeDegreeGate{}, 2) })
+	assert.Empty(t, inner.errors)
+	assert.Len(t, inner.fatals, 1)
+}
+
+func TestGateRegistry(t *testing.T) {
+	var minusOne fr.Element
+	minusOne.SetOne()
+	minusOne.Neg(&minusOne)
+
+	gates := []circuit.Gate{
+		IdentityGate{},
+		NewCipherGate(fr.NewElement(25)),
+		NewCipherGate(minusOne),
+		AddGate{},
+		MulGate{},
+		NewSumGate(4),
+		NewProductGate(3),
+		NewLinearCombinationGate([]fr.Element{fr.NewElement(2), minusOne}, minusOne),
+	}
 
+	for _, gate := range gates {
+		decoded, err := circuit.GateFromID(gate.ID())
+		assert.NoError(t, err)
+		assert.Equal(t, gate, decoded)
+	}
+
+	_, err := circuit.GateFromID("NotAGate")
+	assert.Error(t, err)
+	_, err = circuit.GateFromID("CipherGate-notanumber")
+	assert.Error(t, err)
+}
+
+func TestExpressionGate(t *testing.T) {
+	ark := fr.NewElement(25)
+	cipher := NewCipherGate(ark)
+	expr := FromExpression(Pow(Sum(Input(0), Input(1), Constant(ark)), 7))
+
+	CheckGate(t, expr)
+	assert.Equal(t, cipher.Degree(), expr.Degree())
+	assert.Equal(t, 2, expr.Arity())
+
+	// Evaluates the same as the cipher gate
+	size := 10
 	l := common.RandomFrArray(size)
 	r := common.RandomFrArray(size)
-
 	resA := make([]fr.Element, size)
 	resB := make([]fr.Element, size)
+	cipher.EvalBatch(resA, l, r)
+	expr.EvalBatch(resB, l, r)
+	assert.Equal(t, resA, resB)
 
-	gate.EvalBatch(resA, l, r)
+	// Degrees of composite expressions
+	var minusOne fr.Element
+	minusOne.SetOne()
+	minusOne.Neg(&minusOne)
+	composite := FromExpression(Sub(
+		Prod(Input(0), Pow(Input(2), 3), Constant(minusOne)),
+		Sum(Input(1), Constant(fr.NewElement(3))),
+	))
+	CheckGate(t, composite)
+	assert.Equal(t, 4, composite.Degree())
+	assert.Equal(t, 3, composite.Arity())
 
-	for i := range resB {
-		gate.Eval(&resB[i], &l[i], &r[i])
+	// Constant subexpressions are folded
+	assert.Equal(t, "add(x0,8)", Sum(Input(0), Pow(Constant(fr.NewElement(2)), 3)).String())
+
+	// The ID can be parsed back
+	for _, gate := range []*ExpressionGate{expr, composite} {
+		decoded, err := circuit.GateFromID(gate.ID())
+		assert.NoError(t, err)
+		assert.Equal(t, gate.ID(), decoded.ID())
 	}
 
-	assert.Equal(t, resA, resB)
+	for _, invalid := range []string{"", "x", "add(x0", "pow(x0,0)", "sub(x0)", "foo(x0)", "add(x0,x1))"} {
+		_, err := ParseExpression(invalid)
+		assert.Error(t, err, invalid)
+	}
 }
 
-func TestGates(t *testing.T) {
+func TestGateLibrary(t *testing.T) {
+	var minusOne fr.Element
+	minusOne.SetOne()
+	minusOne.Neg(&minusOne)
+
 	gates := []circuit.Gate{
-		IdentityGate{},
-		NewCipherGate(fr.NewElement(25)),
+		SubGate{},
+		NewScaledAddGate(minusOne),
+		SquareGate{},
+		NewPowGate(fr.NewElement(5), 3),
+		NewPowGate(fr.NewElement(5), 5),
+		NewPowGate(minusOne, 7),
+		SelectGate{},
+		XorGate{},
+		AndGate{},
+		NotGate{},
+		NewLinearCombinationGate([]fr.Element{fr.NewElement(3), fr.NewElement(4)}, fr.NewElement(0)),
 	}
 
 	for _, gate := range gates {
-		genericTest(t, gate)
+		CheckGate(t, gate)
+
+		decoded, err := circuit.GateFromID(gate.ID())
+		assert.NoError(t, err)
+		assert.Equal(t, gate, decoded)
 	}
+
+	// (x + c)^7 is the cipher gate with a null key
+	ark := fr.NewElement(25)
+	var x, resA, resB, zero fr.Element
+	x.SetUint64(11)
+	NewPowGate(ark, 7).Eval(&resA, &x)
+	NewCipherGate(ark).Eval(&resB, &zero, &x)
+	assert.Equal(t, resA, resB)
+
+	// Truth tables of the boolean gates
+	bits := []fr.Element{fr.NewElement(0), fr.NewElement(1)}
+	for a := range bits {
+		var res fr.Element
+		NotGate{}.Eval(&res, &bits[a])
+		assert.Equal(t, bits[1-a], res)
+		for b := range bits {
+			XorGate{}.Eval(&res, &bits[a], &bits[b])
+			assert.Equal(t, bits[a^b], res)
+			AndGate{}.Eval(&res, &bits[a], &bits[b])
+			assert.Equal(t, bits[a&b], res)
+			SelectGate{}.Eval(&res, &bits[1], &bits[a], &bits[b])
+			assert.Equal(t, bits[a], res)
+			SelectGate{}.Eval(&res, &bits[0], &bits[a], &bits[b])
+			assert.Equal(t, bits[b], res)
+		}
+	}
+
+	_, err := circuit.GateFromID("PowGate-4;1")
+	assert.Error(t, err)
+	assert.Panics(t, func() { NewPowGate(fr.NewElement(1), 2) })
 }
@@ -0,0 +1,102 @@
+package gates
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/AlexandreBelling/gnark/frontend"
+	"github.com/consensys/gkr-mimc/circuit"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+)
+
+// LinearCombinationGate returns c0 * x0 + c1 * x1 + ... + constant
+type LinearCombinationGate struct {
+	Coeffs   []fr.Element
+	Constant fr.Element
+}
+
+// NewLinearCombinationGate returns a gate computing the linear combination of its
+// inputs with `coeffs` and adding `constant`. There is one input per coefficient.
+func NewLinearCombinationGate(coeffs []fr.Element, constant fr.Element) *LinearCombinationGate {
+	if len(coeffs) < 1 {
+		panic("a linear combination gate needs at least one coefficient")
+	}
+	return &LinearCombinationGate{Coeffs: append([]fr.Element{}, coeffs...), Constant: constant}
+}
+
+// ID returns the ID of the gate, it is of the form `LinCombGate-c0,c1,...;constant`
+func (l *LinearCombinationGate) ID() string {
+	coeffs := make([]string, len(l.Coeffs))
+	for i := range l.Coeffs {
+		coeffs[i] = l.Coeffs[i].String()
+	}
+	return fmt.Sprintf("LinCombGate-%v;%v", strings.Join(coeffs, ","), l.Constant.String())
+}
+
+// Arity returns the number of inputs of the gate
+func (l *LinearCombinationGate) Arity() int { return len(l.Coeffs) }
+
+// EvalBatch returns the linear combination for a range of inputs
+func (l *LinearCombinationGate) EvalBatch(res []fr.Element, xs ...[]fr.Element) {
+	var tmp fr.Element
+	for i := range res {
+		res[i] = l.Constant
+	}
+	for k, x := range xs {
+		for i := range res {
+			tmp.Mul(&x[i], &l.Coeffs[k])
+			res[i].Add(&res[i], &tmp)
+		}
+	}
+}
+
+// Eval returns c0 * x0 + c1 * x1 + ... + constant
+func (l *LinearCombinationGate) Eval(res *fr.Element, xs ...*fr.Element) {
+	var acc, tmp fr.Element
+	acc.Set(&l.Constant)
+	for k, x := range xs {
+		tmp.Mul(x, &l.Coeffs[k])
+		acc.Add(&acc, &tmp)
+	}
+	res.Set(&acc)
+}
+
+// GnarkEval performs the linear combination on gnark variables
+// The multiplications by constants do not cost any constraint.
+func (l *LinearCombinationGate) GnarkEval(cs frontend.API, xs ...frontend.Variable) frontend.Variable {
+	terms := make([]frontend.Variable, len(xs))
+	for k := range xs {
+		terms[k] = cs.Mul(xs[k], frontend.Variable(l.Coeffs[k]))
+	}
+	return cs.Add(frontend.Variable(l.Constant), terms[0], terms[1:]...)
+}
+
+// Degree returns the degree of the gate
+func (l *LinearCombinationGate) Degree() (degHPrime int) {
+	return 1
+}
+
+// newLinearCombinationGateFromParams rebuilds a linear combination from `c0,c1,...;constant`
+func newLinearCombinationGateFromParams(params string) (circuit.Gate, error) {
+	parts := strings.Split(params, ";")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("expected `coeffs;constant`, got %q", params)
+	}
+
+	coeffStrs := strings.Split(parts[0], ",")
+	coeffs := make([]fr.Element, len(coeffStrs))
+	for i := range coeffStrs {
+		c, err := parseElement(coeffStrs[i])
+		if err != nil {
+			return nil, err
+		}
+		coeffs[i] = c
+	}
+
+	constant, err := parseElement(parts[1])
+	if err != nil {
+		return nil, err
+	}
+
+	return NewLinearCombinationGate(coeffs, constant), nil
+}
@@ -1,41 +1,93 @@
 package gates
 
 import (
+	"fmt"
+
 	"github.com/AlexandreBelling/gnark/frontend"
 	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
 )
 
-// MulGate performs a multiplication
+// MulGate performs a multiplication of its two inputs
 type MulGate struct{}
 
 // ID returns the MulGate as ID
 func (m MulGate) ID() string { return "MulGate" }
 
+// Arity returns the number of inputs of the gate
+func (m MulGate) Arity() int { return 2 }
+
+// EvalBatch returns vL * vR for a range of inputs
+func (m MulGate) EvalBatch(res []fr.Element, xs ...[]fr.Element) {
+	ls := xs[0]
+	rs := xs[1]
+	for i := range res {
+		res[i].Mul(&ls[i], &rs[i])
+	}
+}
+
 // Eval returns vL * vR
-func (m MulGate) Eval(res, vL, vR *fr.Element) {
-	res.Mul(vL, vR)
+func (m MulGate) Eval(res *fr.Element, xs ...*fr.Element) {
+	res.Mul(xs[0], xs[1])
 }
 
 // GnarkEval performs the gate operation on gnark variables
-func (m MulGate) GnarkEval(cs frontend.API, vL, vR frontend.Variable) frontend.Variable {
-	return cs.Mul(vL, vR)
+func (m MulGate) GnarkEval(cs frontend.API, xs ...frontend.Variable) frontend.Variable {
+	return cs.Mul(xs[0], xs[1])
+}
+
+// Degree returns the degree of the gate
+func (m MulGate) Degree() (degHPrime int) {
+	return 2
+}
+
+// ProductGate returns the product of an arbitrary number of inputs
+type ProductGate struct {
+	NbInputs int
+}
+
+// NewProductGate returns a gate multiplying `nbInputs` inputs
+func NewProductGate(nbInputs int) *ProductGate {
+	if nbInputs < 1 {
+		panic(fmt.Sprintf("a product gate needs at least one input, got %v", nbInputs))
+	}
+	return &ProductGate{NbInputs: nbInputs}
+}
+
+// ID returns the ID of the gate, including its number of inputs
+func (p *ProductGate) ID() string { return fmt.Sprintf("ProductGate-%v", p.NbInputs) }
+
+// Arity returns the number of inputs of the gate
+func (p *ProductGate) Arity() int { return p.NbInputs }
+
+// EvalBatch returns x0 * x1 * ... for a range of inputs
+func (p *ProductGate) EvalBatch(res []fr.Element, xs ...[]fr.Element) {
+	copy(res, xs[0])
+	for _, x := range xs[1:] {
+		for i := range res {
+			res[i].Mul(&res[i], &x[i])
+		}
+	}
 }
 
-// EvalManyVR performs an element-wise multiplication of many vRs values by one vL value
-func (m MulGate) EvalManyVR(res []fr.Element, vL *fr.Element, vRs []fr.Element) {
-	for i, vR := range vRs {
-		res[i].Mul(vL, &vR)
+// Eval returns x0 * x1 * ...
+func (p *ProductGate) Eval(res *fr.Element, xs ...*fr.Element) {
+	var tmp fr.Element
+	tmp.Set(xs[0])
+	for _, x := range xs[1:] {
+		tmp.Mul(&tmp, x)
 	}
+	res.Set(&tmp)
 }
 
-// EvalManyVL performs an element-wise multiplication of many vLs values by one vR value
-func (m MulGate) EvalManyVL(res, vLs []fr.Element, vR *fr.Element) {
-	for i, vL := range vLs {
-		res[i].Mul(&vL, vR)
+// GnarkEval performs the product on gnark variables
+func (p *ProductGate) GnarkEval(cs frontend.API, xs ...frontend.Variable) frontend.Variable {
+	if len(xs) == 1 {
+		return xs[0]
 	}
+	return cs.Mul(xs[0], xs[1], xs[2:]...)
 }
 
-// Degrees returns the degrees of the gate on hL, hR and hPrime
-func (m MulGate) Degrees() (degHL, degHR, degHPrime int) {
-	return 1, 1, 2
+// Degree returns the degree of the gate : one per input
+func (p *ProductGate) Degree() (degHPrime int) {
+	return p.NbInputs
 }
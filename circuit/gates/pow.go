@@ -0,0 +1,139 @@
+package gates
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/AlexandreBelling/gnark/frontend"
+	"github.com/consensys/gkr-mimc/circuit"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+)
+
+// SquareGate returns the square of its input
+type SquareGate struct{}
+
+// ID returns the gate ID
+func (s SquareGate) ID() string { return "SquareGate" }
+
+// Arity returns the number of inputs of the gate
+func (s SquareGate) Arity() int { return 1 }
+
+// EvalBatch returns x^2 for a range of inputs
+func (s SquareGate) EvalBatch(res []fr.Element, xs ...[]fr.Element) {
+	for i := range res {
+		res[i].Square(&xs[0][i])
+	}
+}
+
+// Eval returns x^2
+func (s SquareGate) Eval(res *fr.Element, xs ...*fr.Element) {
+	res.Square(xs[0])
+}
+
+// GnarkEval performs the squaring on gnark variables
+func (s SquareGate) GnarkEval(cs frontend.API, xs ...frontend.Variable) frontend.Variable {
+	return cs.Mul(xs[0], xs[0])
+}
+
+// Degree returns the degree of the gate
+func (s SquareGate) Degree() (degHPrime int) {
+	return 2
+}
+
+// PowGate returns (x + c)^d. With d = 5 or 7, it is the S-box of MiMC-like and Poseidon-like permutations
+type PowGate struct {
+	Constant fr.Element
+	Exponent int
+}
+
+// NewPowGate returns a gate computing `(x + constant)^exponent`. The exponent must be 3, 5 or 7.
+// Only 5 and 7 are coprime with `r - 1`, which makes `x -> x^d` a permutation of BN254's scalar
+// field. Since 3 divides `r - 1`, the cube is only a plain arithmetic gate : it is not a valid S-box.
+func NewPowGate(constant fr.Element, exponent int) *PowGate {
+	if exponent != 3 && exponent != 5 && exponent != 7 {
+		panic(fmt.Sprintf("the exponent of a pow gate must be 3, 5 or 7, got %v", exponent))
+	}
+	return &PowGate{Constant: constant, Exponent: exponent}
+}
+
+// ID returns the ID of the gate, it is of the form `PowGate-d;c`
+func (p *PowGate) ID() string { return fmt.Sprintf("PowGate-%v;%v", p.Exponent, p.Constant.String()) }
+
+// Arity returns the number of inputs of the gate
+func (p *PowGate) Arity() int { return 1 }
+
+// EvalBatch returns (x + c)^d for a range of inputs
+func (p *PowGate) EvalBatch(res []fr.Element, xs ...[]fr.Element) {
+	for i := range res {
+		p.Eval(&res[i], &xs[0][i])
+	}
+}
+
+// Eval returns (x + c)^d
+func (p *PowGate) Eval(res *fr.Element, xs ...*fr.Element) {
+	var x, x2 fr.Element
+	x.Add(xs[0], &p.Constant)
+	x2.Square(&x)
+	switch p.Exponent {
+	case 3:
+		res.Mul(&x2, &x)
+	case 5:
+		res.Square(&x2)
+		res.Mul(res, &x)
+	case 7:
+		res.Mul(&x2, &x)
+		res.Square(res)
+		res.Mul(res, &x)
+	}
+}
+
+// GnarkEval performs the gate operation on gnark variables
+func (p *PowGate) GnarkEval(cs frontend.API, xs ...frontend.Variable) frontend.Variable {
+	x := cs.Add(xs[0], frontend.Variable(p.Constant))
+	x2 := cs.Mul(x, x)
+	switch p.Exponent {
+	case 3:
+		return cs.Mul(x2, x)
+	case 5:
+		return cs.Mul(cs.Mul(x2, x2), x)
+	default:
+		x3 := cs.Mul(x2, x)
+		return cs.Mul(cs.Mul(x3, x3), x)
+	}
+}
+
+// Degree returns the degree of the gate
+func (p *PowGate) Degree() (degHPrime int) {
+	return p.Exponent
+}
+
+// newPowGateFromParams rebuilds a pow gate from `d;c`
+func newPowGateFromParams(params string) (circuit.Gate, error) {
+	parts := strings.Split(params, ";")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("expected `exponent;constant`, got %q", params)
+	}
+	exponent, err := strconv.Atoi(parts[0])
+	if err != nil || (exponent != 3 && exponent != 5 && exponent != 7) {
+		return nil, fmt.Errorf("%q is not a valid exponent", parts[0])
+	}
+	constant, err := parseElement(parts[1])
+	if err != nil {
+		return nil, err
+	}
+	return NewPowGate(constant, exponent), nil
+}
+
+// PartialEvals computes the round polynomials of the sumcheck, see `circuit.SumcheckGate`
+// and `powAccumulator`
+func (p *PowGate) PartialEvals(eq0, eq1 []fr.Element, x0, x1 [][]fr.Element) []fr.Element {
+	acc := newPowAccumulator(p.Exponent)
+	var s0, s1 fr.Element
+	for i := range eq0 {
+		s0.Add(&x0[0][i], &p.Constant)
+		s1.Add(&x1[0][i], &p.Constant)
+		acc.add(&eq0[i], &eq1[i], &s0, &s1)
+	}
+	return acc.evals()
+}
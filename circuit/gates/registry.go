@@ -0,0 +1,78 @@
+package gates
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+
+	"github.com/consensys/gkr-mimc/circuit"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+)
+
+// Registers all the gates of the package so that circuits using them can be decoded
+func init() {
+	circuit.RegisterGate(IdentityGate{})
+	circuit.RegisterGate(AddGate{})
+	circuit.RegisterGate(MulGate{})
+	circuit.RegisterGate(SubGate{})
+	circuit.RegisterGate(SquareGate{})
+	circuit.RegisterGate(SelectGate{})
+	circuit.RegisterGate(XorGate{})
+	circuit.RegisterGate(AndGate{})
+	circuit.RegisterGate(NotGate{})
+	circuit.RegisterGateConstructor("CipherGate", newCipherGateFromParams)
+	circuit.RegisterGateConstructor("Expr", newExpressionGateFromParams)
+	circuit.RegisterGateConstructor("SumGate", newSumGateFromParams)
+	circuit.RegisterGateConstructor("ProductGate", newProductGateFromParams)
+	circuit.RegisterGateConstructor("LinCombGate", newLinearCombinationGateFromParams)
+	circuit.RegisterGateConstructor("ScaledAddGate", newScaledAddGateFromParams)
+	circuit.RegisterGateConstructor("PowGate", newPowGateFromParams)
+}
+
+// newCipherGateFromParams rebuilds a cipher gate from the decimal representation of its ark
+func newCipherGateFromParams(params string) (circuit.Gate, error) {
+	ark, err := parseElement(params)
+	if err != nil {
+		return nil, err
+	}
+	return NewCipherGate(ark), nil
+}
+
+// newSumGateFromParams rebuilds a sum gate from its number of inputs
+func newSumGateFromParams(params string) (circuit.Gate, error) {
+	n, err := parseNbInputs(params)
+	if err != nil {
+		return nil, err
+	}
+	return NewSumGate(n), nil
+}
+
+// newProductGateFromParams rebuilds a product gate from its number of inputs
+func newProductGateFromParams(params string) (circuit.Gate, error) {
+	n, err := parseNbInputs(params)
+	if err != nil {
+		return nil, err
+	}
+	return NewProductGate(n), nil
+}
+
+// parseNbInputs parses a positive number of inputs
+func parseNbInputs(s string) (int, error) {
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("%q is not a valid number of inputs", s)
+	}
+	return n, nil
+}
+
+// parseElement parses a field element as printed by `fr.Element.String()`
+// Note that small negative values are printed with a `-` sign
+func parseElement(s string) (fr.Element, error) {
+	var res fr.Element
+	var b big.Int
+	if _, ok := b.SetString(s, 10); !ok {
+		return res, fmt.Errorf("%q is not a decimal field element", s)
+	}
+	res.SetBigInt(&b)
+	return res, nil
+}
@@ -0,0 +1,95 @@
+package gates
+
+import (
+	"fmt"
+
+	"github.com/AlexandreBelling/gnark/frontend"
+	"github.com/consensys/gkr-mimc/circuit"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+)
+
+// SubGate performs a subtraction of its two inputs
+type SubGate struct{}
+
+// ID returns the gate ID
+func (s SubGate) ID() string { return "SubGate" }
+
+// Arity returns the number of inputs of the gate
+func (s SubGate) Arity() int { return 2 }
+
+// EvalBatch returns vL - vR for a range of inputs
+func (s SubGate) EvalBatch(res []fr.Element, xs ...[]fr.Element) {
+	ls := xs[0]
+	rs := xs[1]
+	for i := range res {
+		res[i].Sub(&ls[i], &rs[i])
+	}
+}
+
+// Eval returns vL - vR
+func (s SubGate) Eval(res *fr.Element, xs ...*fr.Element) {
+	res.Sub(xs[0], xs[1])
+}
+
+// GnarkEval performs the subtraction on gnark variables
+func (s SubGate) GnarkEval(cs frontend.API, xs ...frontend.Variable) frontend.Variable {
+	return cs.Sub(xs[0], xs[1])
+}
+
+// Degree returns the degree of the gate
+func (s SubGate) Degree() (degHPrime int) {
+	return 1
+}
+
+// ScaledAddGate returns vL + c * vR
+type ScaledAddGate struct {
+	Scale fr.Element
+}
+
+// NewScaledAddGate returns a gate adding `scale` times its second input to its first one
+func NewScaledAddGate(scale fr.Element) *ScaledAddGate {
+	return &ScaledAddGate{Scale: scale}
+}
+
+// ID returns the ID of the gate, including its scale
+func (s *ScaledAddGate) ID() string { return fmt.Sprintf("ScaledAddGate-%v", s.Scale.String()) }
+
+// Arity returns the number of inputs of the gate
+func (s *ScaledAddGate) Arity() int { return 2 }
+
+// EvalBatch returns vL + c * vR for a range of inputs
+func (s *ScaledAddGate) EvalBatch(res []fr.Element, xs ...[]fr.Element) {
+	ls := xs[0]
+	rs := xs[1]
+	var tmp fr.Element
+	for i := range res {
+		tmp.Mul(&rs[i], &s.Scale)
+		res[i].Add(&ls[i], &tmp)
+	}
+}
+
+// Eval returns vL + c * vR
+func (s *ScaledAddGate) Eval(res *fr.Element, xs ...*fr.Element) {
+	var tmp fr.Element
+	tmp.Mul(xs[1], &s.Scale)
+	res.Add(xs[0], &tmp)
+}
+
+// GnarkEval performs the gate operation on gnark variables, the scaling is free
+func (s *ScaledAddGate) GnarkEval(cs frontend.API, xs ...frontend.Variable) frontend.Variable {
+	return cs.Add(xs[0], cs.Mul(xs[1], frontend.Variable(s.Scale)))
+}
+
+// Degree returns the degree of the gate
+func (s *ScaledAddGate) Degree() (degHPrime int) {
+	return 1
+}
+
+// newScaledAddGateFromParams rebuilds a scaled addition from the decimal representation of its scale
+func newScaledAddGateFromParams(params string) (circuit.Gate, error) {
+	scale, err := parseElement(params)
+	if err != nil {
+		return nil, err
+	}
+	return NewScaledAddGate(scale), nil
+}
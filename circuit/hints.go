@@ -0,0 +1,52 @@
+package circuit
+
+import (
+	"fmt"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+)
+
+// Names of the hints registered by default
+const (
+	// InverseHint computes the inverse of each instance of its input, zero is mapped to zero
+	InverseHint string = "Inverse"
+	// BitsHint decomposes each instance of its input in bits. The i-th output is the bit i,
+	// starting from the least significant one. The bits above the number of outputs are dropped.
+	BitsHint string = "Bits"
+)
+
+func init() {
+	RegisterHint(InverseHint, inverseHint)
+	RegisterHint(BitsHint, bitsHint)
+}
+
+func inverseHint(outputs [][]fr.Element, inputs ...[]fr.Element) error {
+	if len(inputs) != 1 || len(outputs) != 1 {
+		return fmt.Errorf("expected 1 input and 1 output, got %v and %v", len(inputs), len(outputs))
+	}
+	if len(inputs[0]) != len(outputs[0]) {
+		return fmt.Errorf("the input has size %v but the output has size %v", len(inputs[0]), len(outputs[0]))
+	}
+	// BatchInvert maps zero to zero
+	copy(outputs[0], fr.BatchInvert(inputs[0]))
+	return nil
+}
+
+func bitsHint(outputs [][]fr.Element, inputs ...[]fr.Element) error {
+	if len(inputs) != 1 {
+		return fmt.Errorf("expected 1 input, got %v", len(inputs))
+	}
+	for i := range outputs {
+		if len(inputs[0]) != len(outputs[i]) {
+			return fmt.Errorf("the input has size %v but output %v has size %v", len(inputs[0]), i, len(outputs[i]))
+		}
+	}
+
+	for z := range inputs[0] {
+		x := inputs[0][z].ToRegular()
+		for i := range outputs {
+			outputs[i][z].SetUint64(x.Bit(uint64(i)))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,214 @@
+package circuit
+
+import (
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+)
+
+// Optimize returns a circuit computing the same outputs as `c` with fewer layers, and thus fewer sumchecks.
+// It removes the data-parallel identity layers that are not needed to keep the circuit valid, and fuses
+// data-parallel layers into their only consumer when the degree of the fused gate is at most `maxDegree`.
+//
+// It also returns the positions of the layers of `c` in the optimized circuit : `mapping[l]` is the layer
+// holding the same values as the layer `l` of `c` in the assignment, or -1 if the layer was fused into
+// another one. The input layers are kept in place, but the output layers may be reordered.
+func Optimize(c Circuit, maxDegree int) (Circuit, []int, error) {
+	if err := c.Validate(); err != nil {
+		return nil, nil, err
+	}
+
+	res := c.withoutOuts()
+	mapping := make([]int, len(c))
+	for l := range mapping {
+		mapping[l] = l
+	}
+
+	for changed := true; changed; {
+		changed = false
+		// Starts from the last layers, so that chains of gates are fused from their end
+		for l := len(res) - 1; l >= 0 && !changed; l-- {
+			var candidate Circuit
+			var replaceBy int
+			if res.isRemovableIdentity(l) {
+				candidate, replaceBy = res.removeIdentity(l), res[l].In[0]
+			} else if consumer, ok := res.fusableInto(l, maxDegree); ok {
+				candidate, replaceBy = res.fuse(l, consumer), -1
+			} else {
+				continue
+			}
+
+			// Only keep the changes leaving the circuit valid. The outputs must be kept as well :
+			// a layer consumed by other ones does not become an output once its copy is removed.
+			if err := BuildCircuit(candidate); err != nil || candidate.OutputArity() != c.OutputArity() ||
+				len(candidate.AssertionLayers()) != len(c.AssertionLayers()) {
+				continue
+			}
+
+			for i := range mapping {
+				mapping[i] = shiftedPosition(mapping[i], l, replaceBy)
+			}
+			res = candidate.withoutOuts()
+			changed = true
+		}
+	}
+
+	if err := BuildCircuit(res); err != nil {
+		return nil, nil, err
+	}
+	return res, mapping, nil
+}
+
+// withoutOuts returns a copy of the circuit with empty `Out` fields, so that `BuildCircuit` can recompute them
+func (c Circuit) withoutOuts() Circuit {
+	res := make(Circuit, len(c))
+	for l := range c {
+		res[l] = c[l]
+		res[l].In = append([]int{}, c[l].In...)
+		res[l].Out = nil
+	}
+	return res
+}
+
+// shiftedPosition returns the position of the layer `pos` once the layer `removed` is removed
+// and its consumers read `replaceBy` instead
+func shiftedPosition(pos, removed, replaceBy int) int {
+	switch {
+	case pos == removed:
+		return replaceBy
+	case pos > removed:
+		return pos - 1
+	}
+	return pos
+}
+
+// without returns a copy of the circuit without the layer `l`. Its consumers read `replaceBy` instead.
+func (c Circuit) without(l, replaceBy int) Circuit {
+	res := make(Circuit, 0, len(c)-1)
+	for i := range c {
+		if i == l {
+			continue
+		}
+		layer := c[i]
+		layer.In = make([]int, len(c[i].In))
+		for k, pos := range c[i].In {
+			layer.In[k] = shiftedPosition(pos, l, replaceBy)
+		}
+		if c.IsAdviceLayer(i) {
+			layer.Advice = &Advice{Hint: c[i].Advice.Hint, In: make([]int, len(c[i].Advice.In)), Output: c[i].Advice.Output}
+			for k, pos := range c[i].Advice.In {
+				layer.Advice.In[k] = shiftedPosition(pos, l, replaceBy)
+			}
+		}
+		layer.Out = nil
+		res = append(res, layer)
+	}
+	return res
+}
+
+// isRemovableIdentity returns true if the layer `l` is a data-parallel copy of its input
+func (c Circuit) isRemovableIdentity(l int) bool {
+	return !c.IsInputLayer(l) && !c.IsFixedLayer(l) && !c[l].IsWired() && len(c[l].In) == 1 && isIdentityGate(c[l].Gate)
+}
+
+// removeIdentity removes the identity layer `l` and plugs its input into its consumers
+func (c Circuit) removeIdentity(l int) Circuit {
+	return c.without(l, c[l].In[0])
+}
+
+// consumers returns the layers reading the layer `l`
+func (c Circuit) consumers(l int) []int {
+	res := []int{}
+	for i := l + 1; i < len(c); i++ {
+		for _, pos := range c[i].In {
+			if pos == l {
+				res = append(res, i)
+				break
+			}
+		}
+	}
+	return res
+}
+
+// isReadByHint returns true if the values of the layer `l` are read by the hint of an advice layer
+func (c Circuit) isReadByHint(l int) bool {
+	for i := 0; i < c.InputArity(); i++ {
+		if c.IsAdviceLayer(i) && containsInt(c[i].Advice.In, l) {
+			return true
+		}
+	}
+	return false
+}
+
+// fusableInto returns the only consumer of the layer `l`, if `l` can be fused into it
+func (c Circuit) fusableInto(l, maxDegree int) (consumer int, ok bool) {
+	if c.IsInputLayer(l) || c.IsFixedLayer(l) || c[l].IsWired() || c.isReadByHint(l) {
+		return 0, false
+	}
+	consumers := c.consumers(l)
+	// Output layers have no consumers
+	if len(consumers) != 1 || c[consumers[0]].IsWired() {
+		return 0, false
+	}
+	consumer = consumers[0]
+	// Only one of the inputs of the consumer can be `l`, as the circuit is valid
+	deg := c[consumer].Gate.Degree() * c[l].Gate.Degree()
+	return consumer, deg <= maxDegree
+}
+
+// fuse returns a copy of the circuit where the layer `l` is fused into `consumer`
+func (c Circuit) fuse(l, consumer int) Circuit {
+	// The inputs of the fused layer are the other inputs of the consumer, followed by
+	// the inputs of `l` the consumer does not read already
+	in := []int{}
+	positions := make(map[int]int)
+	addInput := func(pos int) int {
+		if k, ok := positions[pos]; ok {
+			return k
+		}
+		positions[pos] = len(in)
+		in = append(in, pos)
+		return len(in) - 1
+	}
+
+	for _, pos := range c[consumer].In {
+		if pos != l {
+			addInput(pos)
+		}
+	}
+
+	inners := make([]Gate, len(c[consumer].In))
+	inputs := make([][]int, len(c[consumer].In))
+	for k, pos := range c[consumer].In {
+		if pos != l {
+			inputs[k] = []int{positions[pos]}
+			continue
+		}
+		inners[k] = c[l].Gate
+		inputs[k] = make([]int, len(c[l].In))
+		for j, inp := range c[l].In {
+			inputs[k][j] = addInput(inp)
+		}
+	}
+
+	res := c.withoutOuts()
+	res[consumer].Gate = NewFusedGate(c[consumer].Gate, inners, inputs)
+	res[consumer].In = in
+	// The inputs of `l` are all before `l`, so the order of the layers is still topological
+	return res.without(l, -1)
+}
+
+// isIdentityGate returns true if the gate returns its only input. It is tested on two points,
+// as gates of degree one are affine.
+func isIdentityGate(gate Gate) bool {
+	if gate.Degree() != 1 {
+		return false
+	}
+	var x, y fr.Element
+	for _, v := range []uint64{0, 1} {
+		x.SetUint64(v)
+		gate.Eval(&y, &x)
+		if y != x {
+			return false
+		}
+	}
+	return true
+}
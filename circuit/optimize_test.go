@@ -0,0 +1,123 @@
+package circuit_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/consensys/gkr-mimc/circuit"
+	"github.com/consensys/gkr-mimc/circuit/gates"
+	"github.com/consensys/gkr-mimc/common"
+	"github.com/consensys/gkr-mimc/poly"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/stretchr/testify/assert"
+)
+
+// redundantCircuit returns a circuit with an identity layer and a chain of low-degree gates
+func redundantCircuit() (*circuit.Builder, []circuit.Wire, circuit.Circuit) {
+	b := circuit.NewBuilder()
+	x, y, z := b.Input(), b.Input(), b.Input()
+	sum := b.Apply(gates.AddGate{}, x, y)
+	copied := b.Apply(gates.IdentityGate{}, sum)
+	prod := b.Apply(gates.MulGate{}, copied, z)
+	square := b.Apply(gates.FromExpression(gates.Pow(gates.Input(0), 2)), prod)
+	b.Output(square)
+	// The sum is also an output, so it cannot be fused
+	b.Output(sum)
+
+	c, err := b.Build()
+	if err != nil {
+		panic(err)
+	}
+	return b, []circuit.Wire{x, y, z, sum, copied, prod, square}, c
+}
+
+func TestOptimize(t *testing.T) {
+	b, wires, c := redundantCircuit()
+
+	optimized, mapping, err := circuit.Optimize(c, 4)
+	assert.NoError(t, err)
+	assert.Len(t, mapping, len(c))
+	assert.Less(t, len(optimized), len(c))
+
+	inputs := []poly.MultiLin{common.RandomFrArray(8), common.RandomFrArray(8), common.RandomFrArray(8)}
+	a := c.Assign(inputs...)
+	aOpt := optimized.Assign(inputs...)
+
+	// The outputs are the same
+	assert.Equal(t, c.OutputArity(), optimized.OutputArity())
+	for _, w := range []circuit.Wire{wires[3], wires[6]} {
+		o := b.OutputLayer(w)
+		assert.True(t, optimized.IsOutputLayer(mapping[o]))
+		assert.Equal(t, a[o], aOpt[mapping[o]])
+	}
+
+	// The layers still present hold the same values
+	for l := range c {
+		if mapping[l] >= 0 {
+			assert.Equal(t, a[l], aOpt[mapping[l]], "layer %v", l)
+		}
+	}
+
+	// The identity layer holds the same values as its input, and the product is fused into the square
+	assert.Equal(t, mapping[b.LayerOf(wires[3])], mapping[b.LayerOf(wires[4])])
+	assert.Equal(t, -1, mapping[b.LayerOf(wires[5])])
+
+	assert.Less(t, len(optimized), len(c))
+
+	// The fused gates survive the encodings
+	encoded, err := json.Marshal(optimized)
+	assert.NoError(t, err)
+	var decoded circuit.Circuit
+	assert.NoError(t, json.Unmarshal(encoded, &decoded))
+	assert.Equal(t, aOpt, decoded.Assign(inputs...))
+}
+
+func TestOptimizeDegreeBound(t *testing.T) {
+	_, _, c := redundantCircuit()
+
+	// Fusing the product into the square has degree 4
+	optimized, _, err := circuit.Optimize(c, 3)
+	assert.NoError(t, err)
+	for l := range optimized {
+		if optimized[l].Gate != nil {
+			assert.LessOrEqual(t, optimized[l].Gate.Degree(), 3)
+		}
+	}
+
+	// Nothing can be fused in the Mimc rounds, and the copies are needed
+	b := circuit.NewBuilder()
+	key, state := b.Input(), b.Input()
+	for i := 0; i < 3; i++ {
+		state = b.Apply(gates.NewCipherGate(fr.NewElement(uint64(i))), key, state)
+	}
+	b.Output(state)
+	mimc, err := b.Build()
+	assert.NoError(t, err)
+
+	optimized, mapping, err := circuit.Optimize(mimc, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, mimc, optimized)
+	for l := range mapping {
+		assert.Equal(t, l, mapping[l])
+	}
+}
+
+func TestFusedGate(t *testing.T) {
+	// (x0 + x1) * x0
+	fused := circuit.NewFusedGate(gates.MulGate{}, []circuit.Gate{gates.AddGate{}, nil}, [][]int{{0, 1}, {0}})
+	assert.Equal(t, 2, fused.Arity())
+	assert.Equal(t, 2, fused.Degree())
+
+	x, y := fr.NewElement(3), fr.NewElement(5)
+	var res fr.Element
+	fused.Eval(&res, &x, &y)
+	assert.Equal(t, fr.NewElement(24), res)
+
+	batch := make([]fr.Element, 1)
+	fused.EvalBatch(batch, []fr.Element{x}, []fr.Element{y})
+	assert.Equal(t, res, batch[0])
+
+	decoded, err := circuit.GateFromID(fused.ID())
+	assert.NoError(t, err)
+	assert.Equal(t, fused, decoded)
+}
@@ -0,0 +1,111 @@
+package circuit
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// GateConstructor rebuilds a parameterised gate from the parameter part of its ID.
+// For an ID of the form `<prefix>-<params>`, it is passed `<params>`.
+type GateConstructor func(params string) (Gate, error)
+
+// gateRegistry maps gate IDs to gates, so that a circuit can be rebuilt from its encoding.
+// Gates without parameters are registered directly, parameterised gates are registered
+// by the prefix of their ID along with a constructor.
+var gateRegistry = struct {
+	sync.RWMutex
+	gates        map[string]Gate
+	constructors map[string]GateConstructor
+}{
+	gates:        make(map[string]Gate),
+	constructors: make(map[string]GateConstructor),
+}
+
+// RegisterGate registers a gate that does not take parameters under its ID.
+// Registering twice the same ID overwrites the previous entry.
+func RegisterGate(gate Gate) {
+	gateRegistry.Lock()
+	defer gateRegistry.Unlock()
+	gateRegistry.gates[gate.ID()] = gate
+}
+
+// RegisterGateConstructor registers a constructor for all the gates whose
+// ID is of the form `<prefix>-<params>`. The prefix cannot contain a `-`.
+func RegisterGateConstructor(prefix string, constructor GateConstructor) {
+	if strings.Contains(prefix, "-") {
+		panic(fmt.Sprintf("gate prefix %q cannot contain a `-`", prefix))
+	}
+	gateRegistry.Lock()
+	defer gateRegistry.Unlock()
+	gateRegistry.constructors[prefix] = constructor
+}
+
+// GateFromID returns the gate whose `ID()` is `id`, using the gate registry.
+// The returned gate is guaranteed to return exactly `id` when calling `ID()`.
+func GateFromID(id string) (Gate, error) {
+	// The lock is not held while calling the constructor, as it may itself
+	// look up the IDs of other gates
+	gateRegistry.RLock()
+	gate, isRegistered := gateRegistry.gates[id]
+	sep := strings.Index(id, "-")
+	var constructor GateConstructor
+	var ok bool
+	if sep >= 0 {
+		constructor, ok = gateRegistry.constructors[id[:sep]]
+	}
+	gateRegistry.RUnlock()
+
+	// Exact matches have the priority
+	if isRegistered {
+		return gate, nil
+	}
+
+	if sep < 0 {
+		return nil, fmt.Errorf("unknown gate %q : it is not registered", id)
+	}
+
+	prefix, params := id[:sep], id[sep+1:]
+	if !ok {
+		return nil, fmt.Errorf("unknown gate %q : no constructor is registered for %q", id, prefix)
+	}
+
+	gate, err := constructor(params)
+	if err != nil {
+		return nil, fmt.Errorf("could not build gate %q : %v", id, err)
+	}
+
+	// Sanity-check : otherwise, the decoded circuit would not be the encoded one
+	if gate.ID() != id {
+		return nil, fmt.Errorf("the constructor for %q built a gate with ID %q", id, gate.ID())
+	}
+
+	return gate, nil
+}
+
+// hintRegistry maps hint names to hints, so that `Assign` can run the hints of the advice layers
+var hintRegistry = struct {
+	sync.RWMutex
+	hints map[string]Hint
+}{
+	hints: make(map[string]Hint),
+}
+
+// RegisterHint registers a hint under `name`, so that it can be used by advice layers.
+// Registering twice the same name overwrites the previous entry.
+func RegisterHint(name string, hint Hint) {
+	hintRegistry.Lock()
+	defer hintRegistry.Unlock()
+	hintRegistry.hints[name] = hint
+}
+
+// HintFromName returns the hint registered under `name`
+func HintFromName(name string) (Hint, error) {
+	hintRegistry.RLock()
+	defer hintRegistry.RUnlock()
+	hint, ok := hintRegistry.hints[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown hint %q : it is not registered", name)
+	}
+	return hint, nil
+}
@@ -0,0 +1,204 @@
+package circuit
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/consensys/gkr-mimc/poly"
+)
+
+// Kinds of errors returned by `Validate`. They can be tested with `errors.Is`
+var (
+	ErrEmptyCircuit          = errors.New("the circuit has no layers")
+	ErrInputOutOfRange       = errors.New("input index out of range")
+	ErrNotTopological        = errors.New("inputs are not in topological order")
+	ErrDuplicateInput        = errors.New("the same layer is used twice as input")
+	ErrMissingGate           = errors.New("layers must have either both inputs and a gate, or none of them")
+	ErrInputsNotPrefix       = errors.New("input layers must come before all other layers")
+	ErrArityMismatch         = errors.New("the arity of the gate does not match the number of inputs")
+	ErrInconsistentOut       = errors.New("out is inconsistent with the inputs of the other layers")
+	ErrMultiOutputInputLayer = errors.New("input layers must have exactly one output")
+	ErrOutputLayer           = errors.New("the circuit must have at least one output layer")
+	ErrUnsupportedDegree     = errors.New("unsupported gate degree")
+	ErrInvalidWiring         = errors.New("invalid wiring")
+	ErrInconsistentSizes     = errors.New("inconsistent layer sizes")
+	ErrInvalidFixed          = errors.New("invalid fixed layer")
+	ErrInvalidAdvice         = errors.New("invalid advice layer")
+	ErrInvalidAssertion      = errors.New("invalid assertion layer")
+)
+
+// LayerError is the error returned by `Validate`. It names the offending layer
+// and wraps one of the error kinds above.
+type LayerError struct {
+	Layer   int
+	Kind    error
+	Details string
+}
+
+func (e *LayerError) Error() string {
+	if len(e.Details) == 0 {
+		return fmt.Sprintf("layer %v : %v", e.Layer, e.Kind)
+	}
+	return fmt.Sprintf("layer %v : %v (%v)", e.Layer, e.Kind, e.Details)
+}
+
+// Unwrap returns the kind of the error
+func (e *LayerError) Unwrap() error {
+	return e.Kind
+}
+
+func layerErrorf(layer int, kind error, details string, args ...interface{}) *LayerError {
+	return &LayerError{Layer: layer, Kind: kind, Details: fmt.Sprintf(details, args...)}
+}
+
+// Validate checks that the circuit is well-formed and can be processed by the
+// prover and the verifier. It returns a `*LayerError` describing the first issue found.
+// The `Out` fields must have been computed already (e.g. by `BuildCircuit`).
+func (c Circuit) Validate() error {
+
+	if len(c) == 0 {
+		return &LayerError{Layer: 0, Kind: ErrEmptyCircuit}
+	}
+
+	// The sumcheck interpolates polynomials of degree `gate.Degree() + 1`
+	// on `gate.Degree() + 2` points
+	maxDegree := poly.MaxDomainSize - 2
+	nInputLayers := 0
+	expectedOuts := make([][]int, len(c))
+
+	for l := range c {
+		isInput := len(c[l].In) == 0
+
+		if isInput != (c[l].Gate == nil) {
+			return layerErrorf(l, ErrMissingGate, "has %v inputs and gate %v", len(c[l].In), c[l].Gate)
+		}
+
+		if c.IsFixedLayer(l) {
+			if !isInput || c[l].IsWired() || c[l].Size != 0 || c.IsAdviceLayer(l) {
+				return layerErrorf(l, ErrInvalidFixed, "fixed layers cannot have inputs, a gate, a wiring, a size or an advice")
+			}
+			if n := len(c[l].Fixed); n&(n-1) != 0 {
+				return layerErrorf(l, ErrInvalidFixed, "has %v values, which is not a power of two", n)
+			}
+			continue
+		}
+
+		if isInput {
+			if c[l].IsWired() || c[l].Size != 0 {
+				return layerErrorf(l, ErrInvalidWiring, "input layers cannot have a wiring or a size")
+			}
+			if nInputLayers != l {
+				return layerErrorf(l, ErrInputsNotPrefix, "layer %v is not an input layer", nInputLayers)
+			}
+			if err := c.validateAdvice(l); err != nil {
+				return err
+			}
+			nInputLayers++
+			continue
+		}
+
+		if c.IsAdviceLayer(l) {
+			return layerErrorf(l, ErrInvalidAdvice, "only input layers can be computed by a hint")
+		}
+
+		seen := make(map[int]struct{}, len(c[l].In))
+		for _, inp := range c[l].In {
+			if inp < 0 || inp >= len(c) {
+				return layerErrorf(l, ErrInputOutOfRange, "input %v, the circuit has %v layers", inp, len(c))
+			}
+			if inp >= l {
+				return layerErrorf(l, ErrNotTopological, "reads layer %v", inp)
+			}
+			if _, ok := seen[inp]; ok {
+				return layerErrorf(l, ErrDuplicateInput, "reads layer %v several times", inp)
+			}
+			seen[inp] = struct{}{}
+			expectedOuts[inp] = append(expectedOuts[inp], l)
+		}
+
+		if g, ok := c[l].Gate.(interface{ Arity() int }); ok && g.Arity() != len(c[l].In) {
+			return layerErrorf(l, ErrArityMismatch, "gate %v takes %v inputs but the layer has %v", c[l].Gate.ID(), g.Arity(), len(c[l].In))
+		}
+
+		if deg := c[l].Gate.Degree(); deg < 0 || deg > maxDegree {
+			return layerErrorf(l, ErrUnsupportedDegree, "gate %v has degree %v, the maximum is %v", c[l].Gate.ID(), deg, maxDegree)
+		}
+
+		if c[l].IsWired() {
+			if err := c.validateWiring(l); err != nil {
+				return err
+			}
+		} else if c[l].Size != 0 {
+			return layerErrorf(l, ErrInconsistentSizes, "only wired layers can set their size")
+		}
+	}
+
+	if nInputLayers == 0 {
+		return layerErrorf(0, ErrInputsNotPrefix, "the circuit has no input layer")
+	}
+	if c.ExternalInputArity() == 0 {
+		return layerErrorf(0, ErrInvalidAdvice, "all the input layers are advice layers")
+	}
+
+	// The hints can read any layer, but not the ones depending on their own result
+	if _, err := c.evaluationOrder(); err != nil {
+		return err
+	}
+
+	if nInputLayers == len(c) {
+		return layerErrorf(len(c)-1, ErrOutputLayer, "all layers are input layers")
+	}
+
+	for l := range c {
+		if !sort.IntsAreSorted(c[l].Out) || !equalInts(c[l].Out, expectedOuts[l]) {
+			return layerErrorf(l, ErrInconsistentOut, "out is %v, expected %v", c[l].Out, expectedOuts[l])
+		}
+		if c[l].Assertion && (len(c[l].Out) > 0 || l < nInputLayers || c.IsFixedLayer(l)) {
+			return layerErrorf(l, ErrInvalidAssertion, "only layers computed by a gate and used by no other layer can be assertions")
+		}
+		if c.IsFixedLayer(l) && len(c[l].Out) == 0 {
+			return layerErrorf(l, ErrInvalidFixed, "is not used by any layer")
+		}
+
+		if l < nInputLayers && len(c[l].Out) != 1 {
+			return layerErrorf(l, ErrMultiOutputInputLayer, "has %v outputs, use intermediary copy layers instead", len(c[l].Out))
+		}
+	}
+
+	return nil
+}
+
+// validateWiring checks the wiring of the layer `l`
+func (c Circuit) validateWiring(l int) error {
+	arity := len(c[l].In)
+	if arity > MaxWiredArity {
+		return layerErrorf(l, ErrInvalidWiring, "has %v inputs, the maximum for a wired layer is %v", arity, MaxWiredArity)
+	}
+	if size := c[l].Size; size < 0 || size&(size-1) != 0 {
+		return layerErrorf(l, ErrInconsistentSizes, "the size %v is not a power of two", size)
+	}
+	if len(c[l].Wiring) == 0 {
+		return layerErrorf(l, ErrInvalidWiring, "the wiring has no entries")
+	}
+	if err := c[l].Wiring.check(arity); err != nil {
+		return layerErrorf(l, ErrInvalidWiring, "%v", err)
+	}
+	if _, err := MultilinearCoefficients(c[l].Gate, arity); err != nil {
+		return layerErrorf(l, ErrInvalidWiring, "%v", err)
+	}
+	return nil
+}
+
+// equalInts returns true if the two slices contain the same integers in the same order
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,187 @@
+package circuit_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/consensys/gkr-mimc/circuit"
+	"github.com/consensys/gkr-mimc/circuit/gates"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+
+	cipher := gates.NewCipherGate(fr.NewElement(1))
+	input := circuit.Layer{In: []int{}}
+	fixed := circuit.Layer{In: []int{}, Fixed: []fr.Element{fr.NewElement(1), fr.NewElement(2)}}
+	wiring := circuit.Wiring{{Out: 0, In: []int{1, 0}}, {Out: 1, In: []int{0, 1}}}
+
+	testCases := []struct {
+		name  string
+		c     circuit.Circuit
+		kind  error
+		layer int
+	}{
+		{
+			name: "valid",
+			c:    circuit.Circuit{input, input, {In: []int{0, 1}, Gate: cipher}},
+		},
+		{
+			name: "empty",
+			c:    circuit.Circuit{},
+			kind: circuit.ErrEmptyCircuit,
+		},
+		{
+			name:  "out of range",
+			c:     circuit.Circuit{input, input, {In: []int{0, 3}, Gate: cipher}},
+			kind:  circuit.ErrInputOutOfRange,
+			layer: 2,
+		},
+		{
+			name:  "not topological",
+			c:     circuit.Circuit{input, {In: []int{2}, Gate: gates.IdentityGate{}}, {In: []int{0}, Gate: gates.IdentityGate{}}},
+			kind:  circuit.ErrNotTopological,
+			layer: 1,
+		},
+		{
+			name:  "duplicate input",
+			c:     circuit.Circuit{input, {In: []int{0}, Gate: gates.IdentityGate{}}, {In: []int{1, 1}, Gate: cipher}},
+			kind:  circuit.ErrDuplicateInput,
+			layer: 2,
+		},
+		{
+			name:  "gate without inputs",
+			c:     circuit.Circuit{input, {In: []int{}, Gate: gates.IdentityGate{}}},
+			kind:  circuit.ErrMissingGate,
+			layer: 1,
+		},
+		{
+			name:  "inputs not prefix",
+			c:     circuit.Circuit{input, {In: []int{0}, Gate: gates.IdentityGate{}}, input, {In: []int{1, 2}, Gate: cipher}},
+			kind:  circuit.ErrInputsNotPrefix,
+			layer: 2,
+		},
+		{
+			name:  "arity",
+			c:     circuit.Circuit{input, {In: []int{0}, Gate: cipher}},
+			kind:  circuit.ErrArityMismatch,
+			layer: 1,
+		},
+		{
+			name:  "multi-output input layer",
+			c:     circuit.Circuit{input, input, {In: []int{0, 1}, Gate: cipher}, {In: []int{0, 2}, Gate: cipher}},
+			kind:  circuit.ErrMultiOutputInputLayer,
+			layer: 0,
+		},
+		{
+			name: "several outputs",
+			c:    circuit.Circuit{input, input, {In: []int{0}, Gate: gates.IdentityGate{}}, {In: []int{1}, Gate: gates.IdentityGate{}}},
+		},
+		{
+			name:  "no outputs",
+			c:     circuit.Circuit{input},
+			kind:  circuit.ErrOutputLayer,
+			layer: 0,
+		},
+		{
+			name:  "degree",
+			c:     circuit.Circuit{input, {In: []int{0}, Gate: gates.FromExpression(gates.Pow(gates.Input(0), 11))}},
+			kind:  circuit.ErrUnsupportedDegree,
+			layer: 1,
+		},
+		{
+			name: "wired",
+			c:    circuit.Circuit{input, input, {In: []int{0, 1}, Gate: gates.MulGate{}, Wiring: wiring}},
+		},
+		{
+			name:  "wiring with the wrong arity",
+			c:     circuit.Circuit{input, {In: []int{0}, Gate: gates.IdentityGate{}, Wiring: wiring}},
+			kind:  circuit.ErrInvalidWiring,
+			layer: 1,
+		},
+		{
+			name:  "wiring with a non-multilinear gate",
+			c:     circuit.Circuit{input, input, {In: []int{0, 1}, Gate: cipher, Wiring: wiring}},
+			kind:  circuit.ErrInvalidWiring,
+			layer: 2,
+		},
+		{
+			name:  "empty wiring",
+			c:     circuit.Circuit{input, input, {In: []int{0, 1}, Gate: gates.MulGate{}, Wiring: circuit.Wiring{}}},
+			kind:  circuit.ErrInvalidWiring,
+			layer: 2,
+		},
+		{
+			name: "sized",
+			c:    circuit.Circuit{input, input, {In: []int{0, 1}, Gate: gates.MulGate{}, Wiring: wiring, Size: 2}},
+		},
+		{
+			name:  "size not a power of two",
+			c:     circuit.Circuit{input, input, {In: []int{0, 1}, Gate: gates.MulGate{}, Wiring: wiring, Size: 3}},
+			kind:  circuit.ErrInconsistentSizes,
+			layer: 2,
+		},
+		{
+			name:  "sized data-parallel layer",
+			c:     circuit.Circuit{input, {In: []int{0}, Gate: gates.IdentityGate{}, Size: 2}},
+			kind:  circuit.ErrInconsistentSizes,
+			layer: 1,
+		},
+		{
+			name: "fixed",
+			c:    circuit.Circuit{input, fixed, {In: []int{0, 1}, Gate: cipher}},
+		},
+		{
+			name:  "fixed layer with inputs",
+			c:     circuit.Circuit{input, {In: []int{0}, Gate: gates.IdentityGate{}, Fixed: fixed.Fixed}, {In: []int{0, 1}, Gate: cipher}},
+			kind:  circuit.ErrInvalidFixed,
+			layer: 1,
+		},
+		{
+			name:  "fixed size not a power of two",
+			c:     circuit.Circuit{input, {In: []int{}, Fixed: make([]fr.Element, 3)}, {In: []int{0, 1}, Gate: cipher}},
+			kind:  circuit.ErrInvalidFixed,
+			layer: 1,
+		},
+		{
+			name:  "unused fixed layer",
+			c:     circuit.Circuit{input, fixed, {In: []int{0}, Gate: gates.IdentityGate{}}},
+			kind:  circuit.ErrInvalidFixed,
+			layer: 1,
+		},
+		{
+			name:  "no input layer",
+			c:     circuit.Circuit{fixed, {In: []int{0}, Gate: gates.IdentityGate{}}},
+			kind:  circuit.ErrInputsNotPrefix,
+			layer: 0,
+		},
+	}
+
+	for _, tc := range testCases {
+		err := circuit.BuildCircuit(tc.c)
+
+		if tc.kind == nil {
+			assert.NoError(t, err, tc.name)
+			continue
+		}
+
+		var layerErr *circuit.LayerError
+		assert.True(t, errors.As(err, &layerErr), "%v : got %v", tc.name, err)
+		assert.True(t, errors.Is(err, tc.kind), "%v : got %v", tc.name, err)
+		if layerErr != nil {
+			assert.Equal(t, tc.layer, layerErr.Layer, tc.name)
+		}
+	}
+}
+
+func TestValidateInconsistentOut(t *testing.T) {
+	c := circuit.Circuit{
+		{In: []int{}},
+		{In: []int{0}, Gate: gates.IdentityGate{}},
+	}
+	assert.NoError(t, circuit.BuildCircuit(c))
+
+	c[0].Out = []int{}
+	assert.True(t, errors.Is(c.Validate(), circuit.ErrInconsistentOut))
+}
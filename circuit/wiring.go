@@ -0,0 +1,253 @@
+package circuit
+
+import (
+	"fmt"
+
+	"github.com/consensys/gkr-mimc/poly"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+)
+
+// MaxWiredArity is the maximal number of inputs of a layer with a wiring
+const MaxWiredArity int = 2
+
+// WiringEntry states that the instance `Out` of a layer reads the instance `In[k]`
+// of its k-th input layer.
+type WiringEntry struct {
+	Out int
+	In  []int
+}
+
+// Wiring is a sparse description of the wiring predicate of a layer. When a layer has
+// a wiring, the value of its instance `z` is the sum of `Gate(X_0[In[0]], ..., X_k[In[k]])`
+// over all the entries such that `Out == z` (and zero if there are none). In this
+// case, the gate must be multilinear and the layer can have at most `MaxWiredArity` inputs.
+// The inputs and the layer itself can have different sizes, see `Layer.Size`.
+//
+// Layers with no wiring are data-parallel : the instance `z` only reads the instances `z`
+// of its input layers.
+type Wiring []WiringEntry
+
+// check returns an error if the wiring is malformed for a layer with `arity` inputs
+func (w Wiring) check(arity int) error {
+	for i, e := range w {
+		if len(e.In) != arity {
+			return fmt.Errorf("entry %v has %v inputs, expected %v", i, len(e.In), arity)
+		}
+		if e.Out < 0 {
+			return fmt.Errorf("entry %v has a negative output instance %v", i, e.Out)
+		}
+		for _, inp := range e.In {
+			if inp < 0 {
+				return fmt.Errorf("entry %v has a negative input instance %v", i, inp)
+			}
+		}
+	}
+	return nil
+}
+
+// ShiftWiring returns the wiring of a layer of size `n` whose instance `z` reads the instance
+// `z - shifts[k]` of its k-th input. The instances for which one of them is out of range
+// are not wired, and are thus zero.
+func ShiftWiring(n int, shifts ...int) Wiring {
+	res := Wiring{}
+	for z := 0; z < n; z++ {
+		entry := WiringEntry{Out: z, In: make([]int, len(shifts))}
+		inRange := true
+		for k, shift := range shifts {
+			entry.In[k] = z - shift
+			inRange = inRange && entry.In[k] >= 0 && entry.In[k] < n
+		}
+		if inRange {
+			res = append(res, entry)
+		}
+	}
+	return res
+}
+
+// RotationWiring is the same as `ShiftWiring` but the instances wrap around :
+// the instance `z` reads the instance `z - shifts[k] mod n` of its k-th input.
+func RotationWiring(n int, shifts ...int) Wiring {
+	res := make(Wiring, n)
+	for z := range res {
+		res[z] = WiringEntry{Out: z, In: make([]int, len(shifts))}
+		for k, shift := range shifts {
+			res[z].In[k] = ((z-shift)%n + n) % n
+		}
+	}
+	return res
+}
+
+// PermutationWiring returns the wiring of a layer whose instance `z` reads the instance
+// `perms[k][z]` of its k-th input. It panics if there are no permutations, if they do not
+// all have the same length, or if one of them is not a permutation of `0, ..., n-1`.
+func PermutationWiring(perms ...[]int) Wiring {
+	if len(perms) == 0 {
+		panic("PermutationWiring needs at least one permutation")
+	}
+
+	n := len(perms[0])
+	for k := range perms {
+		if len(perms[k]) != n {
+			panic(fmt.Sprintf("permutation %v has length %v but permutation 0 has length %v", k, len(perms[k]), n))
+		}
+		seen := make([]bool, n)
+		for z, x := range perms[k] {
+			if x < 0 || x >= n {
+				panic(fmt.Sprintf("permutation %v maps %v to %v, which is out of range [0, %v)", k, z, x, n))
+			}
+			if seen[x] {
+				panic(fmt.Sprintf("permutation %v reaches %v twice", k, x))
+			}
+			seen[x] = true
+		}
+	}
+
+	res := make(Wiring, n)
+	for z := range res {
+		res[z] = WiringEntry{Out: z, In: make([]int, len(perms))}
+		for k := range perms {
+			res[z].In[k] = perms[k][z]
+		}
+	}
+	return res
+}
+
+// CheckSizes returns an error if the wiring refers to an instance out of a layer
+// of size `outSize` or out of its inputs of sizes `inSizes`
+func (w Wiring) CheckSizes(outSize int, inSizes ...int) error {
+	for i, e := range w {
+		if e.Out >= outSize {
+			return fmt.Errorf("wiring entry %v writes at %v but the layer has size %v", i, e.Out, outSize)
+		}
+		for k, inp := range e.In {
+			if inp >= inSizes[k] {
+				return fmt.Errorf("wiring entry %v reads at %v but its input %v has size %v", i, inp, k, inSizes[k])
+			}
+		}
+	}
+	return nil
+}
+
+// Evaluate returns the evaluation of the wiring of a layer of size `size` on its input layers
+func (w Wiring) Evaluate(gate Gate, size int, inputs ...[]fr.Element) []fr.Element {
+	inSizes := make([]int, len(inputs))
+	for k := range inputs {
+		inSizes[k] = len(inputs[k])
+	}
+	if err := w.CheckSizes(size, inSizes...); err != nil {
+		panic(err)
+	}
+
+	res := poly.MakeLarge(size)
+	for i := range res {
+		res[i].SetZero()
+	}
+
+	var tmp fr.Element
+	xs := make([]*fr.Element, len(inputs))
+
+	for _, e := range w {
+		for k := range xs {
+			xs[k] = &inputs[k][e.In[k]]
+		}
+		gate.Eval(&tmp, xs...)
+		res[e.Out].Add(&res[e.Out], &tmp)
+	}
+
+	return res
+}
+
+// EvalPredicate returns the evaluation of the multilinear extension of the wiring predicate
+//
+//	\sum_{j} recombChal^j \sum_{entries} eq(qPrimes[j], Out) eq(points[0], In[0]) ... eq(points[k], In[k])
+//
+// It is used by the verifier to complete the check of the sumcheck of a wired layer.
+func (w Wiring) EvalPredicate(qPrimes [][]fr.Element, recombChal fr.Element, points ...[]fr.Element) fr.Element {
+	bN := len(qPrimes[0])
+	inSizes := make([]int, len(points))
+	for k := range points {
+		inSizes[k] = 1 << len(points[k])
+	}
+	if err := w.CheckSizes(1<<bN, inSizes...); err != nil {
+		panic(err)
+	}
+
+	// Recombines the eq tables of the qPrimes
+	eqQ := make(poly.MultiLin, 1<<bN)
+	poly.FoldedEqTable(eqQ, qPrimes[0])
+	tmp := make(poly.MultiLin, 1<<bN)
+	multiplier := recombChal
+	for j := 1; j < len(qPrimes); j++ {
+		poly.FoldedEqTable(tmp, qPrimes[j], multiplier)
+		eqQ.Add(eqQ, tmp)
+		multiplier.Mul(&multiplier, &recombChal)
+	}
+
+	eqPoints := make([]poly.MultiLin, len(points))
+	for k := range points {
+		eqPoints[k] = poly.FoldedEqTable(make(poly.MultiLin, 1<<len(points[k])), points[k])
+	}
+
+	var res, term fr.Element
+	for _, e := range w {
+		term = eqQ[e.Out]
+		for k := range eqPoints {
+			term.Mul(&term, &eqPoints[k][e.In[k]])
+		}
+		res.Add(&res, &term)
+	}
+
+	return res
+}
+
+// MultilinearCoefficients returns the coefficients of a gate that is multilinear in its
+// `arity` inputs. `res[mask]` is the coefficient of the product of the inputs whose
+// positions are the bits set in `mask`. It returns an error if the gate is not multilinear.
+func MultilinearCoefficients(gate Gate, arity int) ([]fr.Element, error) {
+	if gate.Degree() > arity {
+		return nil, fmt.Errorf("gate %v has degree %v, it cannot be multilinear in %v inputs", gate.ID(), gate.Degree(), arity)
+	}
+
+	// Evaluates the gate on the corners of the hypercube
+	res := make([]fr.Element, 1<<arity)
+	xs := make([]fr.Element, arity)
+	ptrs := make([]*fr.Element, arity)
+	for mask := range res {
+		for k := range xs {
+			xs[k].SetUint64(uint64(mask >> k & 1))
+			ptrs[k] = &xs[k]
+		}
+		gate.Eval(&res[mask], ptrs...)
+	}
+
+	// Moebius transform : converts the evaluations into coefficients
+	for k := 0; k < arity; k++ {
+		for mask := range res {
+			if mask>>k&1 == 1 {
+				res[mask].Sub(&res[mask], &res[mask^(1<<k)])
+			}
+		}
+	}
+
+	// Sanity-check the coefficients on a point outside of the hypercube
+	var expected, actual, term fr.Element
+	for k := range xs {
+		xs[k].SetUint64(uint64(2*k + 3))
+	}
+	gate.Eval(&actual, ptrs...)
+	for mask := range res {
+		term = res[mask]
+		for k := range xs {
+			if mask>>k&1 == 1 {
+				term.Mul(&term, &xs[k])
+			}
+		}
+		expected.Add(&expected, &term)
+	}
+
+	if expected != actual {
+		return nil, fmt.Errorf("gate %v is not multilinear", gate.ID())
+	}
+
+	return res, nil
+}
@@ -0,0 +1,194 @@
+package circuit_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/consensys/gkr-mimc/circuit"
+	"github.com/consensys/gkr-mimc/circuit/gates"
+	"github.com/consensys/gkr-mimc/common"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWiredAssign(t *testing.T) {
+
+	n := 8
+
+	// Multiplies each instance of x with the next instance of y
+	rotate := circuit.Wiring{}
+	// Sums the instances two by two
+	pairs := circuit.Wiring{}
+	for z := 0; z < n; z++ {
+		rotate = append(rotate, circuit.WiringEntry{Out: z, In: []int{z, (z + 1) % n}})
+		if z < n/2 {
+			pairs = append(pairs, circuit.WiringEntry{Out: z, In: []int{2 * z, 2*z + 1}})
+		}
+	}
+
+	b := circuit.NewBuilder()
+	x, y := b.Input(), b.Input()
+	prod := b.ApplyWired(gates.MulGate{}, rotate, x, y)
+	// Reading twice the same value inserts a copy, which is data-parallel
+	sum := b.ApplyWired(gates.AddGate{}, pairs, prod, prod)
+	b.Output(sum)
+
+	c, err := b.Build()
+	assert.NoError(t, err)
+	assert.True(t, c[b.LayerOf(sum)].IsWired())
+
+	xs := common.RandomFrArray(n)
+	ys := common.RandomFrArray(n)
+	a := c.Assign(xs, ys)
+
+	for z := 0; z < n; z++ {
+		var expected, tmp fr.Element
+		if z < n/2 {
+			expected.Mul(&xs[2*z], &ys[(2*z+1)%n])
+			tmp.Mul(&xs[2*z+1], &ys[(2*z+2)%n])
+			expected.Add(&expected, &tmp)
+		}
+		assert.Equal(t, expected, a[b.LayerOf(sum)][z], "instance %v", z)
+	}
+
+	// The wiring survives the encodings
+	encodedJSON, err := json.Marshal(c)
+	assert.NoError(t, err)
+	var decodedJSON circuit.Circuit
+	assert.NoError(t, json.Unmarshal(encodedJSON, &decodedJSON))
+	assert.Equal(t, c, decodedJSON)
+
+	encodedBin, err := c.MarshalBinary()
+	assert.NoError(t, err)
+	var decodedBin circuit.Circuit
+	assert.NoError(t, decodedBin.UnmarshalBinary(encodedBin))
+	assert.Equal(t, c, decodedBin)
+}
+
+func TestMultilinearCoefficients(t *testing.T) {
+	coeffs, err := circuit.MultilinearCoefficients(gates.NewLinearCombinationGate(
+		[]fr.Element{fr.NewElement(3), fr.NewElement(7)},
+		fr.NewElement(2),
+	), 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []fr.Element{fr.NewElement(2), fr.NewElement(3), fr.NewElement(7), fr.NewElement(0)}, coeffs)
+
+	coeffs, err = circuit.MultilinearCoefficients(gates.MulGate{}, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []fr.Element{fr.NewElement(0), fr.NewElement(0), fr.NewElement(0), fr.NewElement(1)}, coeffs)
+
+	_, err = circuit.MultilinearCoefficients(gates.FromExpression(gates.Pow(gates.Input(0), 2)), 2)
+	assert.Error(t, err)
+}
+
+// sumTree returns a circuit summing its input instances level by level
+// with wired layers of decreasing sizes
+func sumTree(bn int) (*circuit.Builder, circuit.Wire, circuit.Circuit, error) {
+	b := circuit.NewBuilder()
+	level := b.Input()
+	for size := 1 << (bn - 1); size >= 1; size /= 2 {
+		pairs := circuit.Wiring{}
+		for z := 0; z < size; z++ {
+			pairs = append(pairs, circuit.WiringEntry{Out: z, In: []int{2 * z, 2*z + 1}})
+		}
+		level = b.ApplySized(gates.AddGate{}, pairs, size, level, level)
+	}
+	b.Output(level)
+	c, err := b.Build()
+	return b, level, c, err
+}
+
+func TestSizedAssign(t *testing.T) {
+	bn := 4
+	b, root, c, err := sumTree(bn)
+	assert.NoError(t, err)
+
+	sizes, err := c.Sizes(1 << bn)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, sizes[b.OutputLayer(root)])
+
+	bits, err := c.Bits(bn)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, c.OutputBits(bits))
+
+	xs := common.RandomFrArray(1 << bn)
+	a := c.Assign(xs)
+
+	var expected fr.Element
+	for i := range xs {
+		expected.Add(&expected, &xs[i])
+	}
+	assert.Len(t, a[b.OutputLayer(root)], 1)
+	assert.Equal(t, expected, a[b.OutputLayer(root)][0])
+
+	// The sizes survive the encodings
+	encodedJSON, err := json.Marshal(c)
+	assert.NoError(t, err)
+	var decodedJSON circuit.Circuit
+	assert.NoError(t, json.Unmarshal(encodedJSON, &decodedJSON))
+	assert.Equal(t, c, decodedJSON)
+
+	encodedBin, err := c.MarshalBinary()
+	assert.NoError(t, err)
+	var decodedBin circuit.Circuit
+	assert.NoError(t, decodedBin.UnmarshalBinary(encodedBin))
+	assert.Equal(t, c, decodedBin)
+}
+
+func TestSizesErrors(t *testing.T) {
+	input := circuit.Layer{In: []int{}}
+	halve := circuit.Wiring{{Out: 0, In: []int{0}}, {Out: 1, In: []int{3}}}
+
+	// A data-parallel layer reading layers of different sizes
+	c := circuit.Circuit{
+		input,
+		{In: []int{0}, Gate: gates.IdentityGate{}},
+		{In: []int{1}, Gate: gates.IdentityGate{}, Wiring: halve, Size: 2},
+		{In: []int{1, 2}, Gate: gates.AddGate{}},
+	}
+	assert.NoError(t, circuit.BuildCircuit(c))
+
+	_, err := c.Sizes(4)
+	var layerErr *circuit.LayerError
+	assert.True(t, errors.As(err, &layerErr), "got %v", err)
+	assert.True(t, errors.Is(err, circuit.ErrInconsistentSizes), "got %v", err)
+	assert.Equal(t, 3, layerErr.Layer)
+
+	// The wiring reads out of its input
+	_, err = c[:3].Sizes(2)
+	assert.True(t, errors.Is(err, circuit.ErrInconsistentSizes), "got %v", err)
+
+	// The size of the inputs must be a power of two
+	_, err = c[:3].Sizes(6)
+	assert.Error(t, err)
+}
+
+func TestShiftWirings(t *testing.T) {
+	n := 4
+	xs := common.RandomFrArray(n)
+	identity := gates.IdentityGate{}
+
+	shifted := circuit.ShiftWiring(n, 1).Evaluate(identity, n, xs)
+	rotated := circuit.RotationWiring(n, 1).Evaluate(identity, n, xs)
+	permuted := circuit.PermutationWiring([]int{2, 0, 3, 1}).Evaluate(identity, n, xs)
+
+	assert.True(t, shifted[0].IsZero())
+	assert.Equal(t, xs[n-1], rotated[0])
+	for z := 1; z < n; z++ {
+		assert.Equal(t, xs[z-1], shifted[z])
+		assert.Equal(t, xs[z-1], rotated[z])
+	}
+	assert.Equal(t, []fr.Element{xs[2], xs[0], xs[3], xs[1]}, permuted)
+
+	// Several inputs with different shifts
+	assert.Equal(t, circuit.Wiring{{Out: 1, In: []int{1, 0}}, {Out: 2, In: []int{2, 1}}}, circuit.ShiftWiring(3, 0, 1))
+	assert.Equal(t, circuit.WiringEntry{Out: 0, In: []int{1, 2}}, circuit.RotationWiring(3, -1, 1)[0])
+
+	// Malformed permutations
+	assert.Panics(t, func() { circuit.PermutationWiring() })
+	assert.Panics(t, func() { circuit.PermutationWiring([]int{1, 0}, []int{0, 1, 2}) })
+	assert.Panics(t, func() { circuit.PermutationWiring([]int{0, 0, 1}) })
+	assert.Panics(t, func() { circuit.PermutationWiring([]int{0, 3, 1}) })
+	assert.Panics(t, func() { circuit.PermutationWiring([]int{0, -1}) })
+}
@@ -0,0 +1,130 @@
+package examples
+
+import (
+	"fmt"
+
+	"github.com/consensys/gkr-mimc/circuit"
+	"github.com/consensys/gkr-mimc/circuit/gates"
+	"github.com/consensys/gkr-mimc/hash"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+)
+
+// GMimcHasher returns the GMiMC hasher with a state of width `t`, it must be 2, 4 or 8
+func GMimcHasher(t int) *hash.GMimcHasher {
+	switch t {
+	case 2:
+		return &hash.GMimcT2
+	case 4:
+		return &hash.GMimcT4
+	case 8:
+		return &hash.GMimcT8
+	}
+	panic(fmt.Sprintf("no GMiMC hasher with t = %v, expected 2, 4 or 8", t))
+}
+
+// gmimcColumn is a column of the GMiMC state : the value of `wire`, plus the blocks
+// with multiplicities `blocks` and `constant`. The additions are only performed when
+// the column goes through the S-box, so the other columns cost no layer.
+type gmimcColumn struct {
+	wire     circuit.Wire
+	blocks   []int
+	constant fr.Element
+}
+
+// GMimcCircuit returns the GKR circuit of `GMimcHasher(t).UpdateInplace`.
+//
+// The `t` first input layers hold the columns of the state, the `t` next ones the columns of
+// the block. The `t` output layers hold the columns of the updated state, in order.
+//
+// Each round adds the block and the round constant to the state, applies the S-box to the first
+// column and rotates the columns. Only the S-box costs a layer : it is a cipher gate taking the first
+// column and the sum of the blocks it received since its last S-box. These sums are computed once,
+// as they are mostly the sum of the whole block.
+func GMimcCircuit(t int) circuit.Circuit {
+	hasher := GMimcHasher(t)
+
+	var one fr.Element
+	one.SetOne()
+
+	b := circuit.NewBuilder()
+
+	state := make([]circuit.Wire, t)
+	for j := range state {
+		state[j] = b.Input()
+	}
+	block := make([]circuit.Wire, t)
+	for j := range block {
+		block[j] = b.Input()
+	}
+
+	// Sums of blocks, indexed by their multiplicities
+	sums := make(map[string]circuit.Wire)
+	sumOf := func(multiplicities []int) circuit.Wire {
+		key := fmt.Sprint(multiplicities)
+		if w, ok := sums[key]; ok {
+			return w
+		}
+
+		coeffs := []fr.Element{}
+		inputs := []circuit.Wire{}
+		total := 0
+		for k, m := range multiplicities {
+			if m != 0 {
+				coeffs = append(coeffs, fr.NewElement(uint64(m)))
+				inputs = append(inputs, block[k])
+				total += m
+			}
+		}
+
+		// A single block is read directly
+		if total == 1 {
+			sums[key] = inputs[0]
+		} else {
+			sums[key] = b.Apply(gates.NewLinearCombinationGate(coeffs, fr.Element{}), inputs...)
+		}
+		return sums[key]
+	}
+
+	columns := make([]gmimcColumn, t)
+	for j := range columns {
+		columns[j] = gmimcColumn{wire: state[j], blocks: make([]int, t)}
+	}
+
+	for i := 0; i < hasher.NbRounds(); i++ {
+		for j := range columns {
+			columns[j].blocks[j]++
+			columns[j].constant.Add(&columns[j].constant, &hash.Arks[i])
+		}
+
+		sboxed := gmimcColumn{
+			wire:   b.Apply(gates.NewCipherGate(columns[0].constant), columns[0].wire, sumOf(columns[0].blocks)),
+			blocks: make([]int, t),
+		}
+
+		// Circular permutation
+		columns = append(columns[1:], sboxed)
+	}
+
+	// Miyaguchi-Preneel : adds the old state and the block
+	for j := range columns {
+		coeffs := []fr.Element{one, one}
+		inputs := []circuit.Wire{columns[j].wire, state[j]}
+		for k, m := range columns[j].blocks {
+			if k == j {
+				m++
+			}
+			if m != 0 {
+				coeffs = append(coeffs, fr.NewElement(uint64(m)))
+				inputs = append(inputs, block[k])
+			}
+		}
+		b.Output(b.Apply(gates.NewLinearCombinationGate(coeffs, columns[j].constant), inputs...))
+	}
+
+	c, err := b.Build()
+	if err != nil {
+		panic(err)
+	}
+
+	return c
+}
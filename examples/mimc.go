@@ -4,32 +4,75 @@ import (
 	"github.com/consensys/gkr-mimc/circuit"
 	"github.com/consensys/gkr-mimc/circuit/gates"
 	"github.com/consensys/gkr-mimc/hash"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
 )
 
 // MimcCircuit returns the GKR MIMC proving circuit
 func MimcCircuit() circuit.Circuit {
 	nRounds := 91
 
-	c := make(circuit.Circuit, nRounds+3)
+	b := circuit.NewBuilder()
 
 	// Contains the `block` of the permutations
-	c[0] = circuit.Layer{In: []int{}}
+	// As it is used by every round, the builder adds a copy layer for it at index 2
+	block := b.Input()
 	// Contains the initial state of the permutation
-	c[1] = circuit.Layer{In: []int{}}
-	// Multi-instance layer of the key : added explicitly
-	c[2] = circuit.Layer{In: []int{0}, Gate: gates.IdentityGate{}}
+	state := b.Input()
 
 	for i := 0; i < nRounds; i++ {
-		inp := i + 2
-		if i == 0 {
-			// Points to 1 : not the multi-instance
-			inp = 1
+		state = b.Apply(gates.NewCipherGate(hash.Arks[i]), block, state)
+	}
+
+	b.Output(state)
+
+	c, err := b.Build()
+	if err != nil {
+		panic(err)
+	}
+
+	return c
+}
+
+// MimcCircuitWithParams returns the GKR circuit of `m.UpdateInplace`. Like `MimcCircuit`, its
+// first input layer holds the states, that are the keys of the permutation, and the second
+// one holds the blocks. Its output layer holds the updated states.
+//
+// The rounds use cipher gates for the exponent 7, and expression gates otherwise.
+// Unlike `MimcCircuit`, the Miyaguchi-Preneel feed-forward is part of the circuit.
+// Hashers with the exponent 3 only come from `hash.NewCubicMimcHasher` : the circuit then
+// proves a non-invertible round function, see there.
+func MimcCircuitWithParams(m *hash.MimcHasher) circuit.Circuit {
+	b := circuit.NewBuilder()
+
+	state := b.Input()
+	block := b.Input()
+
+	permuted := block
+	for _, ark := range m.Arks() {
+		var gate circuit.Gate
+		if m.Exponent() == 7 {
+			gate = gates.NewCipherGate(ark)
+		} else {
+			gate = gates.FromExpression(gates.Pow(gates.Sum(gates.Input(0), gates.Input(1), gates.Constant(ark)), m.Exponent()))
 		}
+		permuted = b.Apply(gate, state, permuted)
+	}
 
-		c[i+3] = circuit.Layer{In: []int{2, inp}, Gate: gates.NewCipherGate(hash.Arks[i])}
+	if m.Mode() == hash.MimcMiyaguchiPreneel {
+		var one, two fr.Element
+		one.SetOne()
+		two.SetUint64(2)
+		// newState = perm + 2 * state + block
+		permuted = b.Apply(
+			gates.NewLinearCombinationGate([]fr.Element{one, two, one}, fr.Element{}),
+			permuted, state, block,
+		)
 	}
 
-	if err := circuit.BuildCircuit(c); err != nil {
+	b.Output(permuted)
+
+	c, err := b.Build()
+	if err != nil {
 		panic(err)
 	}
 
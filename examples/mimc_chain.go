@@ -0,0 +1,70 @@
+package examples
+
+import (
+	"github.com/consensys/gkr-mimc/circuit"
+	"github.com/consensys/gkr-mimc/circuit/gates"
+	"github.com/consensys/gkr-mimc/hash"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+)
+
+// MimcChainCircuit returns a GKR circuit proving a Merkle-Damgard chain of `2^bN` Mimc updates.
+// The instance `i` updates the state `states[i]` with the block `blocks[i]`, and the chaining
+// `states[i] = newStates[i-1]` is enforced inside the circuit by a shift wiring.
+//
+// Its output layer holds the new states. The differences between the state of each instance
+// and the new state of the previous one are an assertion layer : the GKR verifier rejects the
+// proof if the chain is broken. The initial state of the chain is `states[0]`.
+func MimcChainCircuit(bN int) circuit.Circuit {
+	n := 1 << bN
+
+	var one, two, minusOne fr.Element
+	one.SetOne()
+	two.SetUint64(2)
+	minusOne.Neg(&one)
+
+	b := circuit.NewBuilder()
+
+	// Same layout as `MimcCircuit` : the state is the key of the permutation
+	states := b.Input()
+	blocks := b.Input()
+
+	permuted := blocks
+	for i := 0; i < hash.MimcRounds; i++ {
+		permuted = b.Apply(gates.NewCipherGate(hash.Arks[i]), states, permuted)
+	}
+
+	// Miyaguchi-Preneel : newState = perm + 2 * state + block
+	newStates := b.Apply(
+		gates.NewLinearCombinationGate([]fr.Element{one, two, one}, fr.Element{}),
+		permuted, states, blocks,
+	)
+
+	// links[i] = states[i] - newStates[i-1], and links[0] = 0
+	links := b.ApplyWired(
+		gates.NewLinearCombinationGate([]fr.Element{one, minusOne}, fr.Element{}),
+		circuit.ShiftWiring(n, 0, 1),
+		states, newStates,
+	)
+
+	b.Output(newStates)
+	b.AssertZero(links)
+
+	c, err := b.Build()
+	if err != nil {
+		panic(err)
+	}
+
+	return c
+}
+
+// MimcChainInputs returns the inputs of `MimcChainCircuit` for hashing `blocks`
+// starting from the state `initial`
+func MimcChainInputs(initial fr.Element, blocks []fr.Element) (states []fr.Element) {
+	states = make([]fr.Element, len(blocks))
+	state := initial
+	for i := range blocks {
+		states[i] = state
+		hash.MimcUpdateInplace(&state, blocks[i])
+	}
+	return states
+}
@@ -0,0 +1,49 @@
+package examples
+
+import (
+	"testing"
+
+	"github.com/consensys/gkr-mimc/common"
+	"github.com/consensys/gkr-mimc/gkr"
+	"github.com/consensys/gkr-mimc/hash"
+	"github.com/consensys/gkr-mimc/poly"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+)
+
+func TestMimcChain(t *testing.T) {
+
+	bN := 3
+	c := MimcChainCircuit(bN)
+
+	blocks := common.RandomFrArray(1 << bN)
+	var initial fr.Element
+	states := MimcChainInputs(initial, blocks)
+
+	a := c.Assign(states, blocks)
+	outputLayers := c.OutputLayers()
+	assert.Len(t, outputLayers, 1)
+	assert.Len(t, c.AssertionLayers(), 1)
+	assert.NoError(t, a.Check(c))
+
+	// The last new state is the hash of the blocks
+	newStates := a[outputLayers[0]]
+	assert.Equal(t, hash.MimcHash(blocks), newStates[len(newStates)-1])
+
+	prove := func() (gkr.Proof, []poly.MultiLin, []fr.Element) {
+		a := c.Assign(states, blocks)
+		outputs := []poly.MultiLin{poly.MultiLin(a[outputLayers[0]]).DeepCopy()}
+		qPrime := common.RandomFrArray(bN)
+		return gkr.Prove(c, a, qPrime), outputs, qPrime
+	}
+
+	proof, outputs, qPrime := prove()
+	assert.NoError(t, gkr.Verify(c, proof, []poly.MultiLin{states, blocks}, outputs, qPrime))
+
+	// Breaking the chain breaks the assertion, and the verifier rejects the proof
+	states[3].SetOne()
+	assert.Error(t, c.Assign(states, blocks).Check(c))
+	proof, outputs, qPrime = prove()
+	assert.Error(t, gkr.Verify(c, proof, []poly.MultiLin{states, blocks}, outputs, qPrime))
+}
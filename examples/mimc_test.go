@@ -1,10 +1,14 @@
 package examples
 
 import (
+	"encoding/json"
 	"testing"
 
+	"github.com/consensys/gkr-mimc/circuit"
 	"github.com/consensys/gkr-mimc/common"
+	"github.com/consensys/gkr-mimc/gkr"
 	"github.com/consensys/gkr-mimc/hash"
+	"github.com/consensys/gkr-mimc/poly"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
@@ -52,3 +56,105 @@ func TestCircuitForm(t *testing.T) {
 	}
 
 }
+
+func TestCircuitEncoding(t *testing.T) {
+
+	circ := MimcCircuit()
+
+	// JSON round-trip
+	encodedJSON, err := json.Marshal(circ)
+	assert.NoError(t, err)
+
+	var decodedJSON circuit.Circuit
+	assert.NoError(t, json.Unmarshal(encodedJSON, &decodedJSON))
+	assert.Equal(t, circ, decodedJSON)
+
+	// Binary round-trip
+	encodedBin, err := circ.MarshalBinary()
+	assert.NoError(t, err)
+
+	var decodedBin circuit.Circuit
+	assert.NoError(t, decodedBin.UnmarshalBinary(encodedBin))
+	assert.Equal(t, circ, decodedBin)
+
+	// The decoded circuit computes the same assignment
+	key, payload := randomInputs(3)
+	a := circ.Assign(key, payload)
+	b := decodedBin.Assign(key, payload)
+	assert.Equal(t, a[93], b[93])
+
+	// Truncated inputs are rejected
+	assert.Error(t, decodedBin.UnmarshalBinary(encodedBin[:len(encodedBin)-1]))
+
+	// So are other versions of the encoding
+	assert.Error(t, json.Unmarshal([]byte(`{"version":2,"layers":[{"in":[]}]}`), &decodedJSON))
+}
+
+func TestChainedMimc(t *testing.T) {
+
+	mimc := MimcCircuit()
+
+	// The output of the first permutation is the state of the second
+	c, firstLayers, secondLayers, err := circuit.Chain(mimc, mimc, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, c.InputArity())
+	assert.Equal(t, firstLayers[93], secondLayers[1])
+	assert.Equal(t, len(c)-1, secondLayers[93])
+
+	key0, payload := randomInputs(2)
+	key1, _ := randomInputs(2)
+	a := c.Assign(key0, payload, key1)
+
+	for i := range payload {
+		intermediate := hash.MimcKeyedPermutation(payload[i], key0[i])
+		final := hash.MimcKeyedPermutation(intermediate, key1[i])
+		assert.Equal(t, intermediate.String(), a[firstLayers[93]][i].String())
+		assert.Equal(t, final.String(), a[secondLayers[93]][i].String())
+	}
+
+	// Reusing the same key for both permutations using the builder
+	b := circuit.NewBuilder()
+	key, state := b.Input(), b.Input()
+	first := b.Inline(mimc, key, state)
+	second := b.Inline(mimc, key, first[93])
+	b.Output(second[93])
+	c, err = b.Build()
+	assert.NoError(t, err)
+
+	a = c.Assign(key0, payload)
+	for i := range payload {
+		final := hash.MimcKeyedPermutation(hash.MimcKeyedPermutation(payload[i], key0[i]), key0[i])
+		assert.Equal(t, final.String(), a[b.LayerOf(second[93])][i].String())
+	}
+
+	_, _, _, err = circuit.Chain(mimc, mimc, 2)
+	assert.Error(t, err)
+}
+
+func TestMimcWithParams(t *testing.T) {
+	bN := 2
+	hashers := []*hash.MimcHasher{
+		hash.DefaultMimc,
+		hash.NewMimcHasher(hash.MimcRounds, 7, hash.Arks, hash.MimcPermutation),
+		hash.NewMimcHasher(20, 5, common.RandomFrArray(20), hash.MimcMiyaguchiPreneel),
+		hash.NewCubicMimcHasher(30, common.RandomFrArray(30), hash.MimcPermutation),
+	}
+
+	for _, m := range hashers {
+		c := MimcCircuitWithParams(m)
+		states, blocks := randomInputs(bN)
+		a := c.Assign(poly.MultiLin(states).DeepCopy(), poly.MultiLin(blocks).DeepCopy())
+		output := c.OutputLayers()[0]
+
+		for i := range states {
+			expected := states[i]
+			m.UpdateInplace(&expected, blocks[i])
+			assert.Equal(t, expected.String(), a[output][i].String())
+		}
+
+		outputs := []poly.MultiLin{poly.MultiLin(a[output]).DeepCopy()}
+		qPrime := common.RandomFrArray(bN)
+		proof := gkr.Prove(c, a, qPrime)
+		assert.NoError(t, gkr.Verify(c, proof, []poly.MultiLin{states, blocks}, outputs, qPrime))
+	}
+}
@@ -0,0 +1,44 @@
+package examples
+
+import (
+	"github.com/consensys/gkr-mimc/circuit"
+	"github.com/consensys/gkr-mimc/circuit/gates"
+	"github.com/consensys/gkr-mimc/hash"
+)
+
+// MimcSpongeCircuit returns the GKR circuit of `hash.MimcSpongePermutation`, circomlib's MiMC-Feistel.
+// Its input layers hold `xL`, `xR` and the key, its output layers hold the permuted `xL` and `xR`.
+//
+// Each round is a single layer computing `xR + (xL + k + c)^5` : the swap of the halves is free.
+func MimcSpongeCircuit() circuit.Circuit {
+	b := circuit.NewBuilder()
+
+	xL := b.Input()
+	xR := b.Input()
+	k := b.Input()
+
+	round := func(c int) circuit.Gate {
+		return gates.FromExpression(gates.Sum(
+			gates.Input(0),
+			gates.Pow(gates.Sum(gates.Input(1), gates.Input(2), gates.Constant(hash.MimcSpongeConstants[c])), 5),
+		))
+	}
+
+	for i := 0; i < hash.MimcSpongeRounds-1; i++ {
+		xL, xR = b.Apply(round(i), xR, xL, k), xL
+	}
+
+	// The last round does not swap. The copy of xL comes first so that the outputs are in order.
+	outL := b.Apply(gates.IdentityGate{}, xL)
+	outR := b.Apply(round(hash.MimcSpongeRounds-1), xR, xL, k)
+
+	b.Output(outL)
+	b.Output(outR)
+
+	c, err := b.Build()
+	if err != nil {
+		panic(err)
+	}
+
+	return c
+}
@@ -0,0 +1,43 @@
+package examples
+
+import (
+	"testing"
+
+	"github.com/consensys/gkr-mimc/common"
+	"github.com/consensys/gkr-mimc/hash"
+	"github.com/consensys/gkr-mimc/poly"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMimcSponge(t *testing.T) {
+	bN := 2
+	n := 1 << bN
+
+	c := MimcSpongeCircuit()
+	assert.Equal(t, 3, c.InputArity())
+	assert.Equal(t, 2, c.OutputArity())
+
+	// The first instance starts the sponge absorbing a random element, with a null capacity and key
+	inputs := make([]poly.MultiLin, 3)
+	for k := range inputs {
+		inputs[k] = common.RandomFrArray(n)
+	}
+	inputs[1][0].SetZero()
+	inputs[2][0].SetZero()
+
+	outputs := proveAndVerify(t, c, bN, inputs)
+
+	for i := 0; i < n; i++ {
+		xL, xR := hash.MimcSpongePermutation(inputs[0][i], inputs[1][i], inputs[2][i])
+		assert.Equal(t, xL.String(), outputs[0][i].String(), "xL of instance %v", i)
+		assert.Equal(t, xR.String(), outputs[1][i].String(), "xR of instance %v", i)
+	}
+
+	// Finishes the sponge natively
+	z := inputs[0][0]
+	r, capacity := outputs[0][0], outputs[1][0]
+	r.Add(&r, &z)
+	r, _ = hash.MimcSpongePermutation(r, capacity, fr.Element{})
+	assert.Equal(t, hash.MimcSpongeHash(z, z), r)
+}
@@ -0,0 +1,114 @@
+package examples
+
+import (
+	"fmt"
+
+	"github.com/consensys/gkr-mimc/circuit"
+	"github.com/consensys/gkr-mimc/circuit/gates"
+	"github.com/consensys/gkr-mimc/hash"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+)
+
+// PoseidonHasher returns the Poseidon hasher with a state of width `t`, it must be 2, 4 or 8
+func PoseidonHasher(t int) *hash.PoseidonHasher {
+	switch t {
+	case 2:
+		return &hash.PoseidonT2
+	case 4:
+		return &hash.PoseidonT4
+	case 8:
+		return &hash.PoseidonT8
+	}
+	panic(fmt.Sprintf("no Poseidon hasher with t = %v, expected 2, 4 or 8", t))
+}
+
+// PoseidonCircuit returns the GKR circuit of `PoseidonHasher(t).Update`.
+//
+// The `t` first input layers hold the columns of the state, the `t` next ones the columns of
+// the block. The `t` output layers hold the columns of the updated state, in order.
+//
+// Each round is made of S-box layers computing `(state + block + ark)^7` with cipher gates, and
+// of linear combination layers computing the MDS product. In partial rounds, the columns without
+// S-box are directly summed by the MDS layers. The last MDS layers also add the old state and the
+// block, as the Miyaguchi-Preneel construction does.
+func PoseidonCircuit(t int) circuit.Circuit {
+	hasher := PoseidonHasher(t)
+	mds := hasher.MDS()
+	nRoundsF, nRoundsP := hasher.NbRounds()
+	nRounds := 2*nRoundsF + nRoundsP
+
+	var one fr.Element
+	one.SetOne()
+
+	b := circuit.NewBuilder()
+
+	state := make([]circuit.Wire, t)
+	for j := range state {
+		state[j] = b.Input()
+	}
+	block := make([]circuit.Wire, t)
+	for j := range block {
+		block[j] = b.Input()
+	}
+
+	current := state
+	for i := 0; i < nRounds; i++ {
+		ark := hash.Arks[i]
+		isFull := i < nRoundsF || i >= nRoundsF+nRoundsP
+
+		// The inputs of the MDS layers, along with the column they come from
+		// and the constant of the column
+		var terms []circuit.Wire
+		var columns []int
+		var constant fr.Element
+
+		for k := 0; k < t; k++ {
+			if isFull || k == 0 {
+				terms = append(terms, b.Apply(gates.NewCipherGate(ark), block[k], current[k]))
+				columns = append(columns, k)
+				continue
+			}
+			// state + block + ark
+			terms = append(terms, current[k], block[k])
+			columns = append(columns, k, k)
+		}
+
+		next := make([]circuit.Wire, t)
+		for j := range next {
+			coeffs := make([]fr.Element, len(terms))
+			for n, k := range columns {
+				coeffs[n] = mds[j][k]
+			}
+
+			constant.SetZero()
+			if !isFull {
+				var tmp fr.Element
+				for k := 1; k < t; k++ {
+					tmp.Mul(&mds[j][k], &ark)
+					constant.Add(&constant, &tmp)
+				}
+			}
+
+			inputs := terms
+			if i == nRounds-1 {
+				// Miyaguchi-Preneel : adds the old state and the block
+				inputs = append(append([]circuit.Wire{}, terms...), state[j], block[j])
+				coeffs = append(coeffs, one, one)
+			}
+
+			next[j] = b.Apply(gates.NewLinearCombinationGate(coeffs, constant), inputs...)
+		}
+		current = next
+	}
+
+	for j := range current {
+		b.Output(current[j])
+	}
+
+	c, err := b.Build()
+	if err != nil {
+		panic(err)
+	}
+
+	return c
+}
@@ -0,0 +1,83 @@
+package examples
+
+import (
+	"fmt"
+
+	"github.com/consensys/gkr-mimc/circuit"
+	"github.com/consensys/gkr-mimc/circuit/gates"
+	"github.com/consensys/gkr-mimc/hash"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+)
+
+// Poseidon2Hasher returns the Poseidon2 hasher with a state of width `t`, it must be 2, 3, 4 or 8
+func Poseidon2Hasher(t int) *hash.Poseidon2Hasher {
+	switch t {
+	case 2:
+		return &hash.Poseidon2T2
+	case 3:
+		return &hash.Poseidon2T3
+	case 4:
+		return &hash.Poseidon2T4
+	case 8:
+		return &hash.Poseidon2T8
+	}
+	panic(fmt.Sprintf("no Poseidon2 hasher with t = %v, expected 2, 3, 4 or 8", t))
+}
+
+// Poseidon2Circuit returns the GKR circuit of `Poseidon2Hasher(t).Permutation`.
+//
+// The `t` input layers hold the columns of the state, and the `t` output layers the columns of
+// the permuted state, in order.
+//
+// Each round is made of S-box layers computing `(x + ark)^5` with pow gates, and of linear
+// combination layers computing the product by the external or the internal matrix. In partial
+// rounds, only the first column goes through an S-box, the others are directly read by the
+// linear combinations.
+func Poseidon2Circuit(t int) circuit.Circuit {
+	hasher := Poseidon2Hasher(t)
+	external := hasher.ExternalMatrix()
+	internal := hasher.InternalMatrix()
+	nRoundsF, nRoundsP := hasher.NbRounds()
+
+	b := circuit.NewBuilder()
+
+	current := make([]circuit.Wire, t)
+	for j := range current {
+		current[j] = b.Input()
+	}
+
+	// Multiplies the columns by a matrix
+	linearLayer := func(mat [][]fr.Element, columns []circuit.Wire) []circuit.Wire {
+		res := make([]circuit.Wire, t)
+		for j := range res {
+			res[j] = b.Apply(gates.NewLinearCombinationGate(mat[j], fr.Element{}), columns...)
+		}
+		return res
+	}
+
+	current = linearLayer(external, current)
+
+	for i, arks := range hasher.Arks() {
+		sBoxes := append([]circuit.Wire{}, current...)
+		for k := range arks {
+			sBoxes[k] = b.Apply(gates.NewPowGate(arks[k], 5), current[k])
+		}
+
+		if i < nRoundsF || i >= nRoundsF+nRoundsP {
+			current = linearLayer(external, sBoxes)
+		} else {
+			current = linearLayer(internal, sBoxes)
+		}
+	}
+
+	for j := range current {
+		b.Output(current[j])
+	}
+
+	c, err := b.Build()
+	if err != nil {
+		panic(err)
+	}
+
+	return c
+}
@@ -0,0 +1,40 @@
+package examples
+
+import (
+	"testing"
+
+	"github.com/consensys/gkr-mimc/common"
+	"github.com/consensys/gkr-mimc/poly"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPoseidon2(t *testing.T) {
+	bN := 2
+	n := 1 << bN
+
+	for _, width := range []int{2, 3, 4, 8} {
+		c := Poseidon2Circuit(width)
+		assert.Equal(t, width, c.InputArity())
+		assert.Equal(t, width, c.OutputArity())
+
+		inputs := make([]poly.MultiLin, width)
+		for k := range inputs {
+			inputs[k] = common.RandomFrArray(n)
+		}
+		outputs := proveAndVerify(t, c, bN, inputs)
+
+		for i := 0; i < n; i++ {
+			state := make([]fr.Element, width)
+			for k := range state {
+				state[k] = inputs[k][i]
+			}
+			Poseidon2Hasher(width).Permutation(state)
+			for k := range state {
+				assert.Equal(t, state[k].String(), outputs[k][i].String(), "t = %v, instance %v, column %v", width, i, k)
+			}
+		}
+	}
+
+	assert.Panics(t, func() { Poseidon2Hasher(5) })
+}
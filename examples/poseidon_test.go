@@ -0,0 +1,39 @@
+package examples
+
+import (
+	"testing"
+
+	"github.com/consensys/gkr-mimc/common"
+	"github.com/consensys/gkr-mimc/poly"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPoseidon(t *testing.T) {
+	bN := 2
+	n := 1 << bN
+
+	for _, width := range []int{2, 4, 8} {
+		c := PoseidonCircuit(width)
+		assert.Equal(t, 2*width, c.InputArity())
+		assert.Equal(t, width, c.OutputArity())
+
+		inputs := make([]poly.MultiLin, 2*width)
+		for k := range inputs {
+			inputs[k] = common.RandomFrArray(n)
+		}
+		outputs := proveAndVerify(t, c, bN, inputs)
+
+		for i := 0; i < n; i++ {
+			state, block := make([]fr.Element, width), make([]fr.Element, width)
+			for j := 0; j < width; j++ {
+				state[j], block[j] = inputs[j][i], inputs[width+j][i]
+			}
+			PoseidonHasher(width).Update(state, block)
+
+			for j := 0; j < width; j++ {
+				assert.Equal(t, state[j].String(), outputs[j][i].String(), "t = %v, column %v of instance %v", width, j, i)
+			}
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package examples
+
+import (
+	"testing"
+
+	"github.com/consensys/gkr-mimc/circuit"
+	"github.com/consensys/gkr-mimc/common"
+	"github.com/consensys/gkr-mimc/gkr"
+	"github.com/consensys/gkr-mimc/poly"
+	"github.com/stretchr/testify/assert"
+)
+
+// proveAndVerify assigns the circuit on `2^bN` instances, proves the assignment with GKR and checks
+// that the proof verifies. It returns the output layers of the assignment, in the order of
+// `c.OutputLayers()`. The inputs are left untouched : the prover and the verifier work on copies.
+func proveAndVerify(t *testing.T, c circuit.Circuit, bN int, inputs []poly.MultiLin) []poly.MultiLin {
+	t.Helper()
+
+	deepCopy := func(tables []poly.MultiLin) []poly.MultiLin {
+		res := make([]poly.MultiLin, len(tables))
+		for k := range tables {
+			res[k] = tables[k].DeepCopy()
+		}
+		return res
+	}
+
+	a := c.Assign(deepCopy(inputs)...)
+
+	outputs := make([]poly.MultiLin, c.OutputArity())
+	for j, o := range c.OutputLayers() {
+		outputs[j] = poly.MultiLin(a[o]).DeepCopy()
+	}
+
+	qPrime := common.RandomFrArray(bN)
+	proof := gkr.Prove(c, a, qPrime)
+	assert.NoError(t, gkr.Verify(c, proof, deepCopy(inputs), deepCopy(outputs), qPrime))
+
+	return outputs
+}
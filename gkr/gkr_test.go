@@ -2,8 +2,11 @@ package gkr
 
 import (
 	"fmt"
+	"reflect"
 	"testing"
 
+	"github.com/consensys/gkr-mimc/circuit"
+	"github.com/consensys/gkr-mimc/circuit/gates"
 	"github.com/consensys/gkr-mimc/common"
 	"github.com/consensys/gkr-mimc/examples"
 	"github.com/consensys/gkr-mimc/poly"
@@ -67,7 +70,7 @@ func TestGKR(t *testing.T) {
 
 		}
 
-		err := Verify(c, proof, []poly.MultiLin{block, initstate}, a[93], qPrime)
+		err := Verify(c, proof, []poly.MultiLin{block, initstate}, []poly.MultiLin{a[93]}, qPrime)
 		if err != nil {
 			panic(fmt.Sprintf("bn = %v error at gkr verifier : %v", bn, err))
 		}
@@ -77,6 +80,291 @@ func TestGKR(t *testing.T) {
 	}
 }
 
+func TestGKRArithmetic(t *testing.T) {
+
+	var minusOne fr.Element
+	minusOne.SetOne()
+	minusOne.Neg(&minusOne)
+
+	c := circuit.Circuit{
+		{In: []int{}},
+		{In: []int{}},
+		{In: []int{0, 1}, Gate: gates.MulGate{}},
+		{In: []int{2}, Gate: gates.IdentityGate{}},
+		{In: []int{2, 3}, Gate: gates.NewSumGate(2)},
+		{In: []int{2, 3, 4}, Gate: gates.NewProductGate(3)},
+		{In: []int{3, 4, 5}, Gate: gates.NewLinearCombinationGate(
+			[]fr.Element{fr.NewElement(2), minusOne, fr.NewElement(3)},
+			fr.NewElement(5),
+		)},
+	}
+
+	if err := circuit.BuildCircuit(c); err != nil {
+		t.Fatal(err)
+	}
+
+	for bn := 0; bn < 8; bn++ {
+		x := common.RandomFrArray(1 << bn)
+		y := common.RandomFrArray(1 << bn)
+		qPrime := common.RandomFrArray(bn)
+
+		a := c.Assign(x, y)
+		outputs := a[6].DeepCopy()
+		proof := Prove(c, a, qPrime)
+
+		err := Verify(c, proof, []poly.MultiLin{x, y}, []poly.MultiLin{outputs}, qPrime)
+		if err != nil {
+			t.Fatalf("bn = %v error at gkr verifier : %v", bn, err)
+		}
+	}
+}
+
+func TestGKRChained(t *testing.T) {
+
+	mimc := examples.MimcCircuit()
+	c, _, _, err := circuit.Chain(mimc, mimc, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bn := 3
+	key0 := common.RandomFrArray(1 << bn)
+	state := common.RandomFrArray(1 << bn)
+	key1 := common.RandomFrArray(1 << bn)
+	qPrime := common.RandomFrArray(bn)
+
+	a := c.Assign(key0, state, key1)
+	outputs := a[len(c)-1].DeepCopy()
+	proof := Prove(c, a, qPrime)
+
+	if err := Verify(c, proof, []poly.MultiLin{key0, state, key1}, []poly.MultiLin{outputs}, qPrime); err != nil {
+		t.Fatalf("error at gkr verifier : %v", err)
+	}
+}
+
+func TestGKRMultipleOutputs(t *testing.T) {
+
+	b := circuit.NewBuilder()
+	x, y := b.Input(), b.Input()
+	prod := b.Apply(gates.MulGate{}, x, y)
+	sum := b.Apply(gates.NewSumGate(2), prod, y)
+	b.Output(prod)
+	b.Output(sum)
+
+	c, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The outputs are passed in the order of the output layers
+	// The copy of `prod` is appended at the end, so `sum` comes first
+	if !reflect.DeepEqual(c.OutputLayers(), []int{b.OutputLayer(sum), b.OutputLayer(prod)}) {
+		t.Fatalf("unexpected output layers %v", c.OutputLayers())
+	}
+
+	bn := 4
+	xs := common.RandomFrArray(1 << bn)
+	ys := common.RandomFrArray(1 << bn)
+	qPrime := common.RandomFrArray(bn)
+
+	a := c.Assign(xs, ys)
+	outputs := []poly.MultiLin{
+		a[b.OutputLayer(sum)].DeepCopy(),
+		a[b.OutputLayer(prod)].DeepCopy(),
+	}
+	proof := Prove(c, a, qPrime)
+
+	if err := Verify(c, proof, []poly.MultiLin{xs, ys}, outputs, qPrime); err != nil {
+		t.Fatalf("error at gkr verifier : %v", err)
+	}
+
+	// Swapping the outputs must make the verifier fail
+	if err := Verify(c, proof, []poly.MultiLin{xs, ys}, []poly.MultiLin{outputs[1], outputs[0]}, qPrime); err == nil {
+		t.Fatalf("the verifier accepted swapped outputs")
+	}
+
+	if err := Verify(c, proof, []poly.MultiLin{xs, ys}, outputs[:1], qPrime); err == nil {
+		t.Fatalf("the verifier accepted a missing output")
+	}
+}
+
+func TestGKRFixed(t *testing.T) {
+
+	bn := 3
+	n := 1 << bn
+
+	rotate := circuit.Wiring{}
+	for z := 0; z < n; z++ {
+		rotate = append(rotate, circuit.WiringEntry{Out: z, In: []int{z, (z + 1) % n}})
+	}
+
+	// The selector is read by a data-parallel and a wired layer
+	b := circuit.NewBuilder()
+	x := b.Input()
+	sel := b.Fixed([]fr.Element{fr.NewElement(1), fr.NewElement(2), fr.NewElement(3), fr.NewElement(4)})
+	prod := b.Apply(gates.MulGate{}, x, sel)
+	shifted := b.ApplyWired(gates.AddGate{}, rotate, prod, sel)
+	res := b.Apply(gates.AddGate{}, shifted, b.Constant(fr.NewElement(7)))
+	b.Output(res)
+
+	c, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	xs := common.RandomFrArray(n)
+	qPrime := common.RandomFrArray(bn)
+
+	a := c.Assign(xs)
+	outputs := []poly.MultiLin{a[b.LayerOf(res)].DeepCopy()}
+	proof := Prove(c, a, qPrime)
+
+	if err := Verify(c, proof, []poly.MultiLin{xs}, outputs, qPrime); err != nil {
+		t.Fatalf("error at gkr verifier : %v", err)
+	}
+
+	// A prover using other values for the selector must be caught
+	a = c.Assign(xs)
+	a[b.LayerOf(sel)][5].SetUint64(42)
+	for l := b.LayerOf(sel) + 1; l < len(c); l++ {
+		if c.IsFixedLayer(l) {
+			continue
+		}
+		inputs := make([][]fr.Element, len(c[l].In))
+		for k, inp := range c[l].In {
+			inputs[k] = a[inp]
+		}
+		a[l] = c[l].Evaluate(inputs...)
+	}
+	outputs = []poly.MultiLin{a[b.LayerOf(res)].DeepCopy()}
+	proof = Prove(c, a, qPrime)
+
+	if err := Verify(c, proof, []poly.MultiLin{xs}, outputs, qPrime); err == nil {
+		t.Fatalf("the verifier accepted wrong fixed values")
+	}
+}
+
+func TestGKRAdvice(t *testing.T) {
+
+	// Checks the inverse of x * y given as advice
+	b := circuit.NewBuilder()
+	x, y := b.Input(), b.Input()
+	prod := b.Apply(gates.MulGate{}, x, y)
+	inv := b.Advice(circuit.InverseHint, 1, prod)[0]
+	check := gates.FromExpression(gates.Sub(gates.Prod(gates.Input(0), gates.Input(1)), gates.Constant(fr.NewElement(1))))
+	b.Output(b.Apply(check, prod, inv))
+
+	c, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bn := 3
+	qPrime := common.RandomFrArray(bn)
+	a := c.Assign(common.RandomFrArray(1<<bn), common.RandomFrArray(1<<bn))
+	// The advice is an input of the verifier
+	inputs := a.Inputs(c)
+	outputs := []poly.MultiLin{make(poly.MultiLin, 1<<bn)}
+	proof := Prove(c, a, qPrime)
+
+	if err := Verify(c, proof, inputs, outputs, qPrime); err != nil {
+		t.Fatalf("error at gkr verifier : %v", err)
+	}
+
+	inputs[2][0].SetOne()
+	if err := Verify(c, proof, inputs, outputs, qPrime); err == nil {
+		t.Fatalf("the verifier accepted a wrong advice")
+	}
+}
+
+func TestGKRAssertion(t *testing.T) {
+
+	// Checks that x is boolean and outputs x * y
+	b := circuit.NewBuilder()
+	x, y := b.Input(), b.Input()
+	isBool := gates.FromExpression(gates.Prod(gates.Input(0), gates.Sub(gates.Input(0), gates.Constant(fr.NewElement(1)))))
+	b.AssertZero(b.Apply(isBool, x))
+	prod := b.Apply(gates.MulGate{}, x, y)
+	b.Output(prod)
+
+	c, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bn := 3
+	xs := make([]fr.Element, 1<<bn)
+	for z := range xs {
+		xs[z].SetUint64(uint64(z % 2))
+	}
+	ys := common.RandomFrArray(1 << bn)
+
+	prove := func() (Proof, []poly.MultiLin, []fr.Element) {
+		qPrime := common.RandomFrArray(bn)
+		a := c.Assign(xs, ys)
+		outputs := []poly.MultiLin{a[b.OutputLayer(prod)].DeepCopy()}
+		return Prove(c, a, qPrime), outputs, qPrime
+	}
+
+	proof, outputs, qPrime := prove()
+	if err := Verify(c, proof, []poly.MultiLin{xs, ys}, outputs, qPrime); err != nil {
+		t.Fatalf("error at gkr verifier : %v", err)
+	}
+
+	// Breaks the assertion
+	xs[3].SetUint64(2)
+	proof, outputs, qPrime = prove()
+	if err := Verify(c, proof, []poly.MultiLin{xs, ys}, outputs, qPrime); err == nil {
+		t.Fatalf("the verifier accepted a broken assertion")
+	}
+}
+
+func TestGKRWired(t *testing.T) {
+
+	bn := 3
+	n := 1 << bn
+
+	// Multiplies each instance of x with the next instance of y
+	rotate := circuit.Wiring{}
+	// Reverses the order of the instances
+	reverse := circuit.Wiring{}
+	for z := 0; z < n; z++ {
+		rotate = append(rotate, circuit.WiringEntry{Out: z, In: []int{z, (z + 1) % n}})
+		reverse = append(reverse, circuit.WiringEntry{Out: z, In: []int{n - 1 - z}})
+	}
+
+	b := circuit.NewBuilder()
+	x, y := b.Input(), b.Input()
+	prod := b.ApplyWired(gates.MulGate{}, rotate, x, y)
+	rev := b.ApplyWired(gates.IdentityGate{}, reverse, prod)
+	// prod has two consumers, so its sumcheck has two claims
+	sum := b.Apply(gates.AddGate{}, rev, prod)
+	b.Output(sum)
+
+	c, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	xs := common.RandomFrArray(n)
+	ys := common.RandomFrArray(n)
+	qPrime := common.RandomFrArray(bn)
+
+	a := c.Assign(xs, ys)
+	outputs := []poly.MultiLin{a[b.OutputLayer(sum)].DeepCopy()}
+	proof := Prove(c, a, qPrime)
+
+	if err := Verify(c, proof, []poly.MultiLin{xs, ys}, outputs, qPrime); err != nil {
+		t.Fatalf("error at gkr verifier : %v", err)
+	}
+
+	// Changing an instance of the inputs must make the verifier fail
+	ys[0].SetOne()
+	if err := Verify(c, proof, []poly.MultiLin{xs, ys}, outputs, qPrime); err == nil {
+		t.Fatalf("the verifier accepted wrong inputs")
+	}
+}
+
 func BenchmarkGkr(b *testing.B) {
 	for bn := 17; bn < 24; bn++ {
 		b.Run(fmt.Sprintf("bn-%v", bn), func(b *testing.B) {
@@ -105,3 +393,139 @@ func benchmarkGkr(b *testing.B, bn int) {
 	})
 
 }
+
+func TestGKRSized(t *testing.T) {
+
+	bn := 4
+
+	// Sums the instances level by level, and outputs the first level and the root
+	b := circuit.NewBuilder()
+	x := b.Input()
+	level, firstLevel := x, circuit.Wire{}
+	for size := 1 << (bn - 1); size >= 1; size /= 2 {
+		pairs := circuit.Wiring{}
+		for z := 0; z < size; z++ {
+			pairs = append(pairs, circuit.WiringEntry{Out: z, In: []int{2 * z, 2*z + 1}})
+		}
+		level = b.ApplySized(gates.AddGate{}, pairs, size, level, level)
+		if size == 1<<(bn-1) {
+			firstLevel = level
+		}
+	}
+	b.Output(firstLevel)
+	b.Output(level)
+
+	c, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bits, err := c.Bits(bn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// qPrime is as large as the largest output
+	qPrime := common.RandomFrArray(c.OutputBits(bits))
+	if len(qPrime) != bn-1 {
+		t.Fatalf("expected %v coordinates for qPrime, got %v", bn-1, len(qPrime))
+	}
+
+	xs := common.RandomFrArray(1 << bn)
+	a := c.Assign(xs)
+
+	outputs := []poly.MultiLin{}
+	for _, o := range c.OutputLayers() {
+		outputs = append(outputs, a[o].DeepCopy())
+	}
+
+	proof := Prove(c, a, qPrime)
+
+	if err := Verify(c, proof, []poly.MultiLin{xs}, outputs, qPrime); err != nil {
+		t.Fatalf("error at gkr verifier : %v", err)
+	}
+
+	// A wrong root must make the verifier fail
+	outputs[len(outputs)-1][0].SetOne()
+	if err := Verify(c, proof, []poly.MultiLin{xs}, outputs, qPrime); err == nil {
+		t.Fatalf("the verifier accepted a wrong output")
+	}
+}
+
+func TestProofSize(t *testing.T) {
+
+	testCases := []struct {
+		name string
+		bn   int
+		c    circuit.Circuit
+	}{
+		{name: "mimc", bn: 3, c: examples.MimcCircuit()},
+		{name: "chain", bn: 3, c: examples.MimcChainCircuit(3)},
+	}
+
+	for _, tc := range testCases {
+		inputs := make([]poly.MultiLin, tc.c.InputArity())
+		for i := range inputs {
+			inputs[i] = common.RandomFrArray(1 << tc.bn)
+		}
+		bits, err := tc.c.Bits(tc.bn)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		proof := Prove(tc.c, tc.c.Assign(inputs...), common.RandomFrArray(tc.c.OutputBits(bits)))
+
+		actual := 0
+		for l := range tc.c {
+			for _, p := range proof.SumcheckProofs[l] {
+				actual += len(p)
+			}
+			actual += len(proof.Claims[l])
+			for _, q := range proof.QPrimes[l] {
+				actual += len(q)
+			}
+		}
+
+		expected, err := tc.c.ProofSize(tc.bn)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected != actual {
+			t.Errorf("%v : expected a proof of %v elements, got %v", tc.name, expected, actual)
+		}
+	}
+}
+
+func TestGKROptimized(t *testing.T) {
+
+	bn := 3
+
+	b := circuit.NewBuilder()
+	x, y, z := b.Input(), b.Input(), b.Input()
+	sum := b.Apply(gates.AddGate{}, x, y)
+	copied := b.Apply(gates.IdentityGate{}, sum)
+	prod := b.Apply(gates.MulGate{}, copied, z)
+	b.Output(b.Apply(gates.MulGate{}, prod, prod))
+
+	c, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	optimized, _, err := circuit.Optimize(c, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(optimized) >= len(c) {
+		t.Fatalf("expected the optimizer to remove layers")
+	}
+
+	inputs := []poly.MultiLin{common.RandomFrArray(1 << bn), common.RandomFrArray(1 << bn), common.RandomFrArray(1 << bn)}
+	qPrime := common.RandomFrArray(bn)
+	a := optimized.Assign(inputs...)
+	outputs := []poly.MultiLin{a[len(optimized)-1].DeepCopy()}
+	proof := Prove(optimized, a, qPrime)
+
+	if err := Verify(optimized, proof, inputs, outputs, qPrime); err != nil {
+		t.Fatalf("error at gkr verifier : %v", err)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"sort"
 
 	"github.com/consensys/gkr-mimc/circuit"
+	"github.com/consensys/gkr-mimc/common"
 	"github.com/consensys/gkr-mimc/sumcheck"
 
 	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
@@ -17,18 +18,29 @@ type Proof struct {
 	QPrimes        [][][]fr.Element
 }
 
-// Prove returns a new prover
+// Prove returns a GKR proof for the assignment. The output and assertion layers of the circuit
+// are evaluated on the same `qPrime`, which has as many coordinates as the largest of these
+// layers has variables. Smaller layers are evaluated on a prefix of `qPrime`.
 func Prove(c circuit.Circuit, a circuit.Assignment, qPrime []fr.Element) (proof Proof) {
 
 	nLayers := len(c)
+	bits, err := c.Bits(common.Log2Ceil(len(a[0])))
+	if err != nil {
+		panic(err)
+	}
+	if len(qPrime) != c.OutputBits(bits) {
+		panic(fmt.Sprintf("qPrime has %v coordinates but the largest output layer has %v variables", len(qPrime), c.OutputBits(bits)))
+	}
 
 	// Allocate the proof
 	proof.Claims = make([][]fr.Element, nLayers)
 	proof.SumcheckProofs = make([]sumcheck.Proof, nLayers)
 	proof.QPrimes = make([][][]fr.Element, nLayers)
 
-	// Passes the initial qPrime inside the proof
-	proof.QPrimes[nLayers-1] = [][]fr.Element{qPrime}
+	// Passes the initial qPrime inside the proof, for every output and assertion layer
+	for _, o := range c.FinalLayers() {
+		proof.QPrimes[o] = [][]fr.Element{qPrime[:bits[o]]}
+	}
 
 	for layer := nLayers - 1; layer >= 0; layer-- {
 
@@ -38,9 +50,14 @@ func Prove(c circuit.Circuit, a circuit.Assignment, qPrime []fr.Element) (proof
 			break
 		}
 
+		if c.IsFixedLayer(layer) {
+			// The verifier evaluates the fixed layers itself
+			continue
+		}
+
 		// Otherwise, we are on for a multi-instance with identity
 		// The fact this is a multi-identity is specified in the circuit description
-		proof.updateWithSumcheck(c, a, layer)
+		proof.updateWithSumcheck(c, a, bits, layer)
 	}
 
 	return
@@ -49,16 +66,30 @@ func Prove(c circuit.Circuit, a circuit.Assignment, qPrime []fr.Element) (proof
 func (p *Proof) updateWithSumcheck(
 	c circuit.Circuit,
 	a circuit.Assignment,
+	bits []int,
 	layer int,
 ) {
 
+	var sumPi sumcheck.Proof
+	var challenges, finalClaims []fr.Element
+
 	// Sumcheck proof
-	sumPi, nextQPrime, finalClaims := sumcheck.Prove(
-		a.InputsOfLayer(c, layer),
-		p.QPrimes[layer],
-		p.Claims[layer],
-		c[layer].Gate,
-	)
+	if c[layer].IsWired() {
+		sumPi, challenges, finalClaims = sumcheck.ProveWired(
+			a.InputsOfLayer(c, layer),
+			p.QPrimes[layer],
+			p.Claims[layer],
+			c[layer].Gate,
+			c[layer].Wiring,
+		)
+	} else {
+		sumPi, challenges, finalClaims = sumcheck.Prove(
+			a.InputsOfLayer(c, layer),
+			p.QPrimes[layer],
+			p.Claims[layer],
+			c[layer].Gate,
+		)
+	}
 
 	p.SumcheckProofs[layer] = sumPi
 
@@ -85,7 +116,22 @@ func (p *Proof) updateWithSumcheck(
 		}
 
 		p.Claims[inpL][writeAt] = finalClaims[i]
-		p.QPrimes[inpL][writeAt] = nextQPrime
+		p.QPrimes[inpL][writeAt] = InputQPrime(c, layer, challenges, bits, i-1)
 
 	}
 }
+
+// InputQPrime returns the point at which the `k`-th input of a layer is evaluated at the end of
+// its sumcheck. For wired layers, each input is evaluated on its own chunk of the challenges,
+// whose length is the number of variables of the input (given by `bits`).
+// For data-parallel layers, all the inputs are evaluated on the same point.
+func InputQPrime(c circuit.Circuit, layer int, challenges []fr.Element, bits []int, k int) []fr.Element {
+	if !c[layer].IsWired() {
+		return challenges
+	}
+	start := 0
+	for _, inp := range c[layer].In[:k] {
+		start += bits[inp]
+	}
+	return challenges[start : start+bits[c[layer].In[k]]]
+}
@@ -12,29 +12,64 @@ import (
 	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
 )
 
+// Verify checks a GKR proof. `outputs` contains the values of the output layers,
+// in the order of `c.OutputLayers()`. All the inputs must have the same size.
+// The assertion layers are checked to be zero : their claims are zero at `qPrime`.
 func Verify(
 	c circuit.Circuit,
 	proof Proof,
 	inputs []poly.MultiLin,
-	outputs poly.MultiLin,
+	outputs []poly.MultiLin,
 	qPrime []fr.Element,
 ) (err error) {
 
 	nLayers := len(c)
+	outputLayers := c.OutputLayers()
 
-	if !reflect.DeepEqual(qPrime, proof.QPrimes[nLayers-1][0]) {
-		return fmt.Errorf("initial qPrime does not match with the proof %v %v",
-			common.FrSliceToString(qPrime),
-			common.FrSliceToString(proof.QPrimes[nLayers-1][0]),
-		)
+	if len(outputs) != len(outputLayers) {
+		return fmt.Errorf("expected %v outputs but got %v", len(outputLayers), len(outputs))
+	}
+
+	for i := range inputs {
+		if len(inputs[i]) != len(inputs[0]) {
+			return fmt.Errorf("input %v has size %v but input 0 has size %v", i, len(inputs[i]), len(inputs[0]))
+		}
 	}
 
-	// Pass the initial claim into the proof, because the prover does not compute it
-	// For a matter of immutability : the old value of the claim is saved so we can put it
-	// back in place before returning
-	oldClaim := proof.Claims[nLayers-1]
-	proof.Claims[nLayers-1] = append(proof.Claims[nLayers-1], outputs.Evaluate(qPrime))
-	defer func() { proof.Claims[nLayers-1] = oldClaim }()
+	bits, err := c.Bits(common.Log2Ceil(len(inputs[0])))
+	if err != nil {
+		return err
+	}
+	if len(qPrime) != c.OutputBits(bits) {
+		return fmt.Errorf("qPrime has %v coordinates but the largest output layer has %v variables", len(qPrime), c.OutputBits(bits))
+	}
+
+	for i, o := range outputLayers {
+		if len(outputs[i]) != 1<<bits[o] {
+			return fmt.Errorf("output %v has size %v, expected %v", i, len(outputs[i]), 1<<bits[o])
+		}
+		if len(proof.QPrimes[o]) != 1 || !reflect.DeepEqual(qPrime[:bits[o]], proof.QPrimes[o][0]) {
+			return fmt.Errorf("initial qPrime does not match with the proof for output layer %v", o)
+		}
+
+		// Pass the initial claim into the proof, because the prover does not compute it
+		// For a matter of immutability : the old value of the claim is saved so we can put it
+		// back in place before returning
+		oldClaim := proof.Claims[o]
+		proof.Claims[o] = append(proof.Claims[o], outputs[i].Evaluate(qPrime[:bits[o]]))
+		defer func(o int) { proof.Claims[o] = oldClaim }(o)
+	}
+
+	for _, o := range c.AssertionLayers() {
+		if len(proof.QPrimes[o]) != 1 || !reflect.DeepEqual(qPrime[:bits[o]], proof.QPrimes[o][0]) {
+			return fmt.Errorf("initial qPrime does not match with the proof for assertion layer %v", o)
+		}
+
+		// The multilinear extension of a zero layer is zero everywhere
+		oldClaim := proof.Claims[o]
+		proof.Claims[o] = append(proof.Claims[o], fr.Element{})
+		defer func(o int) { proof.Claims[o] = oldClaim }(o)
+	}
 
 	for layer := nLayers - 1; layer >= 0; layer-- {
 		if c.IsInputLayer(layer) {
@@ -43,7 +78,14 @@ func Verify(
 			break
 		}
 
-		if err := proof.testSumcheck(c, layer); err != nil {
+		if c.IsFixedLayer(layer) {
+			if err := proof.testFixed(c, layer); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := proof.testSumcheck(c, bits, layer); err != nil {
 			return fmt.Errorf("error at layer %v : %v", layer, err)
 		}
 	}
@@ -60,11 +102,24 @@ func Verify(
 
 func (proof Proof) testSumcheck(
 	c circuit.Circuit,
+	bits []int,
 	layer int,
 ) (err error) {
 
+	// The number of rounds of the sumcheck is fixed by the sizes of the layers
+	nRounds := bits[layer]
+	if c[layer].IsWired() {
+		nRounds = 0
+		for _, inp := range c[layer].In {
+			nRounds += bits[inp]
+		}
+	}
+	if len(proof.SumcheckProofs[layer]) != nRounds {
+		return fmt.Errorf("the sumcheck of layer %v has %v rounds, expected %v", layer, len(proof.SumcheckProofs[layer]), nRounds)
+	}
+
 	// First thing, test the sumcheck
-	nextQprime, nextClaim, recombChal, err := sumcheck.Verify(
+	challenges, nextClaim, recombChal, err := sumcheck.Verify(
 		proof.Claims[layer],
 		proof.SumcheckProofs[layer],
 	)
@@ -77,7 +132,9 @@ func (proof Proof) testSumcheck(
 	// 2 is because in practice, a gate cannot have more than two inputs with our designs
 	subClaims := make([]*fr.Element, 0, 2)
 
-	for _, inpL := range c[layer].In {
+	inputQPrimes := make([][]fr.Element, len(c[layer].In))
+
+	for k, inpL := range c[layer].In {
 
 		// Seach the position of `l` as an output of layer `inpL`
 		// It works because `c[inpL].Out` is guaranteed to be sorted.
@@ -89,7 +146,8 @@ func (proof Proof) testSumcheck(
 			panic(fmt.Sprintf("circuit misformatted, In and Out are inconsistent between layers %v and %v", layer, inpL))
 		}
 
-		if !reflect.DeepEqual(proof.QPrimes[inpL][readAt], nextQprime) {
+		inputQPrimes[k] = InputQPrime(c, layer, challenges, bits, k)
+		if !reflect.DeepEqual(proof.QPrimes[inpL][readAt], inputQPrimes[k]) {
 			return fmt.Errorf("mismatch for qPrimes between sumcheck and proof at layer %v", layer)
 		}
 
@@ -99,13 +157,19 @@ func (proof Proof) testSumcheck(
 	// Run the gate to compute the expected claim
 	c[layer].Gate.Eval(&expectedClaim, subClaims...)
 
-	// Evaluation of eq to be used for testing the consistency with the challenges
-	// Recombines the eq evaluations into a single challenge
-	tmpEvals := make([]fr.Element, len(proof.QPrimes[layer]))
-	for i := range proof.QPrimes[layer] {
-		tmpEvals[i] = poly.EvalEq(proof.QPrimes[layer][i], nextQprime)
+	var eqEval fr.Element
+	if c[layer].IsWired() {
+		// The wiring predicate plays the role of eq
+		eqEval = c[layer].Wiring.EvalPredicate(proof.QPrimes[layer], recombChal, inputQPrimes...)
+	} else {
+		// Evaluation of eq to be used for testing the consistency with the challenges
+		// Recombines the eq evaluations into a single challenge
+		tmpEvals := make([]fr.Element, len(proof.QPrimes[layer]))
+		for i := range proof.QPrimes[layer] {
+			tmpEvals[i] = poly.EvalEq(proof.QPrimes[layer][i], challenges)
+		}
+		eqEval = poly.EvalUnivariate(tmpEvals, recombChal)
 	}
-	eqEval := poly.EvalUnivariate(tmpEvals, recombChal)
 
 	expectedClaim.Mul(&expectedClaim, &eqEval)
 
@@ -116,6 +180,20 @@ func (proof Proof) testSumcheck(
 	return nil
 }
 
+// testFixed checks the claims on a fixed layer against the evaluations of its values
+func (proof Proof) testFixed(c circuit.Circuit, layer int) error {
+	for j := range c[layer].Out {
+		actual := c[layer].EvalFixed(proof.QPrimes[layer][j])
+		if actual != proof.Claims[layer][j] {
+			return fmt.Errorf(
+				"fixed layer check failed \n\tlayer %v \n\tclaim %v \n\teval %v \n\tqPrime %v",
+				layer, proof.Claims[layer][j].String(), actual.String(), common.FrSliceToString(proof.QPrimes[layer][j]),
+			)
+		}
+	}
+	return nil
+}
+
 // Performs one of the GKR checks for the inputs layers
 func (proof Proof) testInitialRound(inps []poly.MultiLin, layer int) error {
 	qPrime := proof.QPrimes[layer][0]
@@ -25,6 +25,12 @@ type GMimcHasher struct {
 	nRounds int // number of rounds of the Mimc hash function
 }
 
+// T returns the width of the state
+func (g *GMimcHasher) T() int { return g.t }
+
+// NbRounds returns the number of rounds of the permutation
+func (g *GMimcHasher) NbRounds() int { return g.nRounds }
+
 // Hash hashes a full message
 func (g *GMimcHasher) Hash(msg []fr.Element) fr.Element {
 	state := make([]fr.Element, g.t)
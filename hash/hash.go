@@ -4,4 +4,7 @@ func init() {
 	initArk()
 	initPoseidon()
 	initGMimc()
+	initMimc()
+	initMimcSponge()
+	initPoseidon2()
 }
@@ -1,6 +1,8 @@
 package hash
 
 import (
+	"encoding/hex"
+	"math/big"
 	"testing"
 
 	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
@@ -25,3 +27,293 @@ func TestMimcCase(t *testing.T) {
 	expectedY.SetString("1808205620575546259657963589762746470347087906694759866517376279978241663265")
 	assert.Equal(t, y, expectedY, "Got %v", y.String())
 }
+
+func TestPoseidonUpdateInPlace(t *testing.T) {
+	block := []fr.Element{fr.NewElement(1), fr.NewElement(2)}
+	state := make([]fr.Element, 2)
+	PoseidonT2.Update(state, block)
+
+	// The state is entirely updated, not only by the first round
+	firstRound := block[0]
+	firstRound.Add(&firstRound, &Arks[0])
+	SBoxInplace(&firstRound)
+	assert.NotEqual(t, firstRound, state[0])
+
+	assert.Equal(t, state[0], PoseidonT2.Hash(block))
+
+	// Regression vectors : they pin the digests since the in-place fix of `Update`, and are not
+	// taken from another implementation
+	msg := []fr.Element{fr.NewElement(1), fr.NewElement(2), fr.NewElement(3), fr.NewElement(4), fr.NewElement(5)}
+	expected := map[*PoseidonHasher]string{
+		&PoseidonT2: "15505919994776409667934076121501939655903135544720549975942142611308689828451",
+		&PoseidonT4: "15188615821364639989462143446970384369274782449229485332196343542880073314360",
+		&PoseidonT8: "2345266434818222568370033664835117262153112418706307916363339626618766871505",
+	}
+	for p, digest := range expected {
+		h := p.Hash(msg)
+		assert.Equal(t, digest, h.String(), "t = %v", p.T())
+	}
+}
+
+func TestMimcHasher(t *testing.T) {
+	inputs := []fr.Element{fr.NewElement(12), fr.NewElement(5)}
+	assert.Equal(t, MimcHash(inputs), DefaultMimc.Hash(inputs))
+
+	// The plain permutation mode does not feed the state forward
+	plain := NewMimcHasher(MimcRounds, 7, Arks, MimcPermutation)
+	var state fr.Element
+	plain.UpdateInplace(&state, inputs[0])
+	assert.Equal(t, MimcKeyedPermutation(inputs[0], fr.Element{}), state)
+
+	// x^5 with a few rounds
+	arks := []fr.Element{fr.NewElement(1), fr.NewElement(2)}
+	small := NewMimcHasher(2, 5, arks, MimcPermutation)
+	var expected fr.Element
+	expected.SetUint64(12 + 1)
+	PowInplace(&expected, 5)
+	expected.Add(&expected, &arks[1])
+	PowInplace(&expected, 5)
+	assert.Equal(t, expected, small.KeyedPermutation(inputs[0], fr.Element{}))
+
+	assert.Panics(t, func() { NewMimcHasher(2, 4, arks, MimcPermutation) })
+	assert.Panics(t, func() { NewMimcHasher(3, 5, arks, MimcPermutation) })
+
+	// The cube is not a permutation of the field : it needs an explicit opt-in
+	assert.Panics(t, func() { NewMimcHasher(2, 3, arks, MimcPermutation) })
+	assert.Equal(t, 3, NewCubicMimcHasher(2, arks, MimcPermutation).Exponent())
+	assert.Panics(t, func() { NewCubicMimcHasher(3, arks, MimcPermutation) })
+}
+
+// The zero values of the Merkle tree of Tornado Cash : the first one is keccak256("tornado")
+// reduced modulo the field, and each next one is MiMCSponge(z, z)
+var tornadoZeros = []string{
+	"2fe54c60d3acabf3343a35b6eba15db4821b340f76e741e2249685ed4899af6c",
+	"256a6135777eee2fd26f54b8b7037a25439d5235caee224154186d2b8a52e31d",
+	"1151949895e82ab19924de92c40a3d6f7bcb60d92b00504b8199613683f0c200",
+	"20121ee811489ff8d61f09fb89e313f14959a0f28bb428a20dba6b0b068b3bdb",
+}
+
+func TestMimcSponge(t *testing.T) {
+	// Constants of circomlib's mimcsponge.circom
+	assert.True(t, MimcSpongeConstants[0].IsZero())
+	assert.True(t, MimcSpongeConstants[MimcSpongeRounds-1].IsZero())
+	assert.Equal(t, "7120861356467848435263064379192047478074060781135320967663101236819528304084", MimcSpongeConstants[1].String())
+	assert.Equal(t, "5024705281721889198577876690145313457398658950011302225525409148828000436681", MimcSpongeConstants[2].String())
+
+	var z fr.Element
+	var b big.Int
+	b.SetString(tornadoZeros[0], 16)
+	z.SetBigInt(&b)
+	for _, expected := range tornadoZeros[1:] {
+		z = MimcSpongeHash(z, z)
+		zBytes := z.Bytes()
+		assert.Equal(t, expected, hex.EncodeToString(zBytes[:]))
+	}
+
+	// Squeezing several outputs continues the permutations
+	outputs := MimcSpongeMultiHash([]fr.Element{fr.NewElement(1), fr.NewElement(2)}, fr.NewElement(3), 2)
+	r, c := MimcSpongePermutation(fr.NewElement(1), fr.Element{}, fr.NewElement(3))
+	r.Add(&r, new(fr.Element).SetUint64(2))
+	r, c = MimcSpongePermutation(r, c, fr.NewElement(3))
+	assert.Equal(t, r, outputs[0])
+	r, _ = MimcSpongePermutation(r, c, fr.NewElement(3))
+	assert.Equal(t, r, outputs[1])
+}
+
+// Output of the Poseidon2 permutation with t = 3 on the state [0, 1, 2], from the test vectors of
+// the reference implementation. The other widths have no published vector for BN254.
+var poseidon2T3Vector = []string{
+	"0bb61d24daca55eebcb1929a82650f328134334da98ea4f847f760054f4a3033",
+	"303b6f7c86d043bfcbcc80214f26a30277a15d3f74ca654992defe7ff8d03570",
+	"1ed25194542b12eef8617361c3ba7c52e660b145994427cc86296242cf766ec8",
+}
+
+func TestPoseidon2(t *testing.T) {
+	// First round constant of the reference instance for t = 3
+	ark := Poseidon2T3.Arks()[0][0].Bytes()
+	assert.Equal(t, "1d066a255517b7fd8bddd3a93f7804ef7f8fcde48bb4c37a59a09a1a97052816", hex.EncodeToString(ark[:]))
+
+	state := []fr.Element{fr.NewElement(0), fr.NewElement(1), fr.NewElement(2)}
+	Poseidon2T3.Permutation(state)
+	for k, expected := range poseidon2T3Vector {
+		sBytes := state[k].Bytes()
+		assert.Equal(t, expected, hex.EncodeToString(sBytes[:]), "entry %v", k)
+	}
+
+	for _, p := range []*Poseidon2Hasher{&Poseidon2T2, &Poseidon2T3, &Poseidon2T4, &Poseidon2T8} {
+		// The matrices agree with the structured multiplications
+		x := []fr.Element{}
+		for k := 0; k < p.T(); k++ {
+			x = append(x, fr.NewElement(uint64(3*k+1)))
+		}
+		y := append([]fr.Element{}, x...)
+		p.externalMatrixInplace(y)
+		assert.Equal(t, y, MatrixMultiplication(p.ExternalMatrix(), x))
+		y = append(y[:0], x...)
+		p.internalMatrixInplace(y)
+		assert.Equal(t, y, MatrixMultiplication(p.InternalMatrix(), x))
+
+		// The length of the message is part of the hash
+		msg := []fr.Element{fr.NewElement(1), fr.NewElement(2), fr.NewElement(3)}
+		assert.NotEqual(t, p.Hash(msg), p.Hash(append(msg, fr.Element{})))
+	}
+
+	// M4 of the paper
+	m4 := [][]uint64{{5, 7, 1, 3}, {4, 6, 1, 1}, {1, 3, 5, 7}, {1, 1, 4, 6}}
+	ext := Poseidon2T4.ExternalMatrix()
+	for i := range m4 {
+		for j := range m4[i] {
+			assert.Equal(t, fr.NewElement(m4[i][j]), ext[i][j])
+		}
+	}
+}
+
+func TestPoseidon2InternalMatrices(t *testing.T) {
+	// The diagonals of the non-standard instances satisfy the criterion of the reference : the
+	// characteristic polynomial of `M_I^k` is irreducible for k = 1, ..., 2t, so that it is also
+	// the minimal polynomial and has degree t
+	for _, p := range []*Poseidon2Hasher{&Poseidon2T4, &Poseidon2T8} {
+		m := p.InternalMatrix()
+		mk := m
+		for k := 1; k <= 2*p.T(); k++ {
+			assert.True(t, isIrreducible(charPoly(mk)), "t = %v, k = %v", p.T(), k)
+			mk = matMul(mk, m)
+		}
+	}
+}
+
+// The polynomials below are given by their coefficients, from the constant one to the leading one
+
+func matMul(a, b [][]fr.Element) [][]fr.Element {
+	res := make([][]fr.Element, len(a))
+	var tmp fr.Element
+	for i := range a {
+		res[i] = make([]fr.Element, len(b[0]))
+		for j := range res[i] {
+			for k := range b {
+				tmp.Mul(&a[i][k], &b[k][j])
+				res[i][j].Add(&res[i][j], &tmp)
+			}
+		}
+	}
+	return res
+}
+
+// charPoly returns the characteristic polynomial of `a`, with the Faddeev-LeVerrier algorithm
+func charPoly(a [][]fr.Element) []fr.Element {
+	n := len(a)
+	c := make([]fr.Element, n+1)
+	c[n].SetOne()
+	m := make([][]fr.Element, n)
+	for i := range m {
+		m[i] = make([]fr.Element, n)
+	}
+	for k := 1; k <= n; k++ {
+		// M_k = A.M_{k-1} + c_{n-k+1}.I
+		m = matMul(a, m)
+		for i := range m {
+			m[i][i].Add(&m[i][i], &c[n-k+1])
+		}
+		// c_{n-k} = -tr(A.M_k) / k
+		am := matMul(a, m)
+		var tr, kInv fr.Element
+		for i := range am {
+			tr.Add(&tr, &am[i][i])
+		}
+		kInv.SetUint64(uint64(k)).Inverse(&kInv)
+		c[n-k].Mul(&tr, &kInv).Neg(&c[n-k])
+	}
+	return c
+}
+
+// isIrreducible runs Rabin's test on a monic polynomial whose degree is a power of two : `f` is irreducible
+// iff it divides x^(p^n) - x and is coprime with x^(p^(n/2)) - x
+func isIrreducible(f []fr.Element) bool {
+	n := len(f) - 1
+	x := []fr.Element{{}, fr.One()}
+
+	xPow := x
+	var half []fr.Element
+	for i := 1; i <= n; i++ {
+		xPow = polyExpMod(xPow, fr.Modulus(), f)
+		if i == n/2 {
+			half = polyTrim(polySub(xPow, x))
+		}
+	}
+	if len(polyTrim(polySub(xPow, x))) != 0 {
+		return false
+	}
+	return len(polyGcd(f, half)) == 1
+}
+
+func polyTrim(a []fr.Element) []fr.Element {
+	for len(a) > 0 && a[len(a)-1].IsZero() {
+		a = a[:len(a)-1]
+	}
+	return a
+}
+
+func polySub(a, b []fr.Element) []fr.Element {
+	res := make([]fr.Element, len(a))
+	copy(res, a)
+	for len(res) < len(b) {
+		res = append(res, fr.Element{})
+	}
+	for i := range b {
+		res[i].Sub(&res[i], &b[i])
+	}
+	return res
+}
+
+// polyMod returns the remainder of the division of `a` by `b`
+func polyMod(a, b []fr.Element) []fr.Element {
+	a = polyTrim(append([]fr.Element{}, a...))
+	b = polyTrim(b)
+	var lInv, q, tmp fr.Element
+	lInv.Inverse(&b[len(b)-1])
+	for len(a) >= len(b) {
+		q.Mul(&a[len(a)-1], &lInv)
+		shift := len(a) - len(b)
+		for i := range b {
+			tmp.Mul(&q, &b[i])
+			a[shift+i].Sub(&a[shift+i], &tmp)
+		}
+		a = polyTrim(a[:len(a)-1])
+	}
+	return a
+}
+
+func polyMulMod(a, b, f []fr.Element) []fr.Element {
+	if len(a) == 0 || len(b) == 0 {
+		return nil
+	}
+	res := make([]fr.Element, len(a)+len(b)-1)
+	var tmp fr.Element
+	for i := range a {
+		for j := range b {
+			tmp.Mul(&a[i], &b[j])
+			res[i+j].Add(&res[i+j], &tmp)
+		}
+	}
+	return polyMod(res, f)
+}
+
+func polyExpMod(a []fr.Element, e *big.Int, f []fr.Element) []fr.Element {
+	res := []fr.Element{fr.One()}
+	for i := e.BitLen() - 1; i >= 0; i-- {
+		res = polyMulMod(res, res, f)
+		if e.Bit(i) == 1 {
+			res = polyMulMod(res, a, f)
+		}
+	}
+	return res
+}
+
+// polyGcd returns a gcd of `a` and `b`, it is a non-zero constant iff they are coprime
+func polyGcd(a, b []fr.Element) []fr.Element {
+	a, b = polyTrim(a), polyTrim(b)
+	for len(b) > 0 {
+		a, b = b, polyMod(a, b)
+	}
+	return a
+}
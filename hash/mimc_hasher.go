@@ -0,0 +1,134 @@
+package hash
+
+import (
+	"fmt"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+)
+
+// MimcMode is the way a `MimcHasher` updates its state with the output of the permutation
+type MimcMode int
+
+const (
+	// MimcMiyaguchiPreneel updates the state as `MimcUpdateInplace` does : the new state is
+	// `Perm_state(block) + 2 * state + block`, i.e. the cipher `E_k(x) = Perm_k(x) + k` in a
+	// Miyaguchi-Preneel construction
+	MimcMiyaguchiPreneel MimcMode = iota
+	// MimcPermutation updates the state with the plain output of the keyed permutation
+	// `Perm_state(block)`
+	MimcPermutation
+)
+
+// MimcHasher is a parameterised instantiation of MiMC : the number of rounds, the exponent of the
+// S-box and the round constants are configurable, to match the instantiations of other libraries.
+// The round `i` of the keyed permutation computes `x <- (x + key + arks[i])^exponent`.
+type MimcHasher struct {
+	nRounds  int
+	exponent int
+	arks     []fr.Element
+	mode     MimcMode
+}
+
+// DefaultMimc is the instantiation used by `MimcHash`
+var DefaultMimc *MimcHasher
+
+func initMimc() {
+	DefaultMimc = NewMimcHasher(MimcRounds, 7, Arks[:MimcRounds], MimcMiyaguchiPreneel)
+}
+
+// NewMimcHasher returns a MiMC hasher with `nRounds` rounds using the S-box `x^exponent`. The exponent must
+// be 5 or 7, and there must be at least `nRounds` round constants.
+//
+// The exponent 3 is rejected : 3 divides `r - 1`, so `x -> x^3` is not a permutation of BN254's scalar field
+// and neither mode would be built on an invertible round function. See `NewCubicMimcHasher` to opt in anyway.
+func NewMimcHasher(nRounds, exponent int, arks []fr.Element, mode MimcMode) *MimcHasher {
+	if exponent != 5 && exponent != 7 {
+		panic(fmt.Sprintf("the exponent of MiMC must be 5 or 7, got %v", exponent))
+	}
+	return newMimcHasher(nRounds, exponent, arks, mode)
+}
+
+// NewCubicMimcHasher returns a MiMC hasher with the S-box `x^3`. It is NOT a permutation of BN254's scalar
+// field : the rounds are not invertible and the security arguments of MiMC do not hold. It only exists to
+// reproduce the digests of foreign instantiations that use the cube on this field.
+func NewCubicMimcHasher(nRounds int, arks []fr.Element, mode MimcMode) *MimcHasher {
+	return newMimcHasher(nRounds, 3, arks, mode)
+}
+
+// newMimcHasher checks the parameters shared by all the exponents
+func newMimcHasher(nRounds, exponent int, arks []fr.Element, mode MimcMode) *MimcHasher {
+	if nRounds < 1 || len(arks) < nRounds {
+		panic(fmt.Sprintf("got %v round constants for %v rounds", len(arks), nRounds))
+	}
+	if mode != MimcMiyaguchiPreneel && mode != MimcPermutation {
+		panic(fmt.Sprintf("unknown MiMC mode %v", mode))
+	}
+	return &MimcHasher{
+		nRounds:  nRounds,
+		exponent: exponent,
+		arks:     append([]fr.Element{}, arks[:nRounds]...),
+		mode:     mode,
+	}
+}
+
+// NbRounds returns the number of rounds of the permutation
+func (m *MimcHasher) NbRounds() int { return m.nRounds }
+
+// Exponent returns the exponent of the S-box
+func (m *MimcHasher) Exponent() int { return m.exponent }
+
+// Arks returns the round constants
+func (m *MimcHasher) Arks() []fr.Element { return m.arks }
+
+// Mode returns how the state is updated
+func (m *MimcHasher) Mode() MimcMode { return m.mode }
+
+// Hash returns the hash of a slice of field elements, the state is initialized to zero
+func (m *MimcHasher) Hash(input []fr.Element) fr.Element {
+	var state fr.Element
+	for _, x := range input {
+		m.UpdateInplace(&state, x)
+	}
+	return state
+}
+
+// UpdateInplace updates the state with a block, see `MimcMode`
+func (m *MimcHasher) UpdateInplace(state *fr.Element, block fr.Element) {
+	perm := m.KeyedPermutation(block, *state)
+	if m.mode == MimcPermutation {
+		state.Set(&perm)
+		return
+	}
+	state.Double(state)
+	state.Add(state, &perm)
+	state.Add(state, &block)
+}
+
+// KeyedPermutation iterates the rounds over x with the key
+func (m *MimcHasher) KeyedPermutation(x fr.Element, key fr.Element) fr.Element {
+	res := x
+	for i := 0; i < m.nRounds; i++ {
+		res.Add(&res, &key)
+		res.Add(&res, &m.arks[i])
+		PowInplace(&res, m.exponent)
+	}
+	return res
+}
+
+// PowInplace computes x^d in-place for d = 3, 5 or 7
+func PowInplace(x *fr.Element, d int) {
+	tmp := *x
+	switch d {
+	case 3:
+		x.Square(x)
+		x.Mul(x, &tmp)
+	case 5:
+		x.Square(x)
+		x.Square(x)
+		x.Mul(x, &tmp)
+	case 7:
+		SBoxInplace(x)
+	default:
+		panic(fmt.Sprintf("unsupported exponent %v", d))
+	}
+}
@@ -0,0 +1,72 @@
+package hash
+
+import (
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"golang.org/x/crypto/sha3"
+)
+
+// MimcSpongeRounds is the number of rounds of the MiMC-Feistel permutation of circomlib
+const MimcSpongeRounds int = 220
+
+// MimcSpongeConstants are the round constants of circomlib's MiMCSponge.
+// The first and the last ones are zero, the others are derived by iterating keccak256
+// from the seed "mimcsponge" and reducing the digests modulo the field.
+var MimcSpongeConstants []fr.Element
+
+func initMimcSponge() {
+	MimcSpongeConstants = make([]fr.Element, MimcSpongeRounds)
+
+	h := sha3.NewLegacyKeccak256()
+	h.Write([]byte("mimcsponge"))
+	digest := h.Sum(nil)
+
+	var c big.Int
+	for i := 1; i < MimcSpongeRounds-1; i++ {
+		h.Reset()
+		h.Write(digest)
+		digest = h.Sum(nil)
+		// SetBigInt reduces modulo the field
+		MimcSpongeConstants[i].SetBigInt(c.SetBytes(digest))
+	}
+}
+
+// MimcSpongePermutation is the MiMC-Feistel permutation of circomlib, with the key `k`. Each round
+// computes `(xL, xR) <- (xR + (xL + k + c)^5, xL)`, except the last one which does not swap the halves.
+func MimcSpongePermutation(xL, xR, k fr.Element) (fr.Element, fr.Element) {
+	var t fr.Element
+	for i := 0; i < MimcSpongeRounds; i++ {
+		t.Add(&xL, &k)
+		t.Add(&t, &MimcSpongeConstants[i])
+		PowInplace(&t, 5)
+		if i < MimcSpongeRounds-1 {
+			xL, xR = *t.Add(&t, &xR), xL
+		} else {
+			xR.Add(&xR, &t)
+		}
+	}
+	return xL, xR
+}
+
+// MimcSpongeMultiHash is circomlib's MiMCSponge : the inputs are absorbed in the rate `R` of a sponge
+// whose capacity `C` is the other half of the permutation, and `nbOutputs` elements are squeezed.
+func MimcSpongeMultiHash(inputs []fr.Element, key fr.Element, nbOutputs int) []fr.Element {
+	var r, c fr.Element
+	for i := range inputs {
+		r.Add(&r, &inputs[i])
+		r, c = MimcSpongePermutation(r, c, key)
+	}
+
+	outputs := []fr.Element{r}
+	for i := 1; i < nbOutputs; i++ {
+		r, c = MimcSpongePermutation(r, c, key)
+		outputs = append(outputs, r)
+	}
+	return outputs
+}
+
+// MimcSpongeHash returns the single output of MiMCSponge with a null key, as used by Tornado-style Merkle trees
+func MimcSpongeHash(inputs ...fr.Element) fr.Element {
+	return MimcSpongeMultiHash(inputs, fr.Element{}, 1)[0]
+}
@@ -63,12 +63,17 @@ func (p *PoseidonHasher) Hash(msg []fr.Element) fr.Element {
 }
 
 // Update uses the poseidon permutation in a Miyaguchi-Preenel
-// construction to create the hash function.
+// construction to create the hash function. The state is updated in place.
 // https://en.wikipedia.org/wiki/One-way_compression_function#Miyaguchi.E2.80.93Preneel
+//
+// Earlier versions only wrote the output of the first round back into `state`, as the rounds
+// reassign it. The digests of `Update` and `Hash` have changed since this was fixed : values
+// computed with an earlier version do not match anymore.
 func (p *PoseidonHasher) Update(state, block []fr.Element) {
 
-	// Deep-copies the state
+	// Deep-copies the state, and keeps the slice to write the result in
 	oldState := append([]fr.Element{}, state...)
+	res := state
 
 	// Runs the cipher
 	for i := 0; i < p.nRoundsF; i++ {
@@ -91,11 +96,21 @@ func (p *PoseidonHasher) Update(state, block []fr.Element) {
 
 	// Recombine with the old state
 	for i := range state {
-		state[i].Add(&state[i], &oldState[i])
-		state[i].Add(&state[i], &block[i])
+		res[i].Add(&state[i], &oldState[i])
+		res[i].Add(&res[i], &block[i])
 	}
 }
 
+// T returns the width of the state
+func (p *PoseidonHasher) T() int { return p.t }
+
+// NbRounds returns the number of full rounds at the beginning and at the end of the permutation,
+// and the number of partial rounds in the middle
+func (p *PoseidonHasher) NbRounds() (nRoundsF, nRoundsP int) { return p.nRoundsF, p.nRoundsP }
+
+// MDS returns the MDS matrix of the linear layer
+func (p *PoseidonHasher) MDS() [][]fr.Element { return p.cauchy }
+
 // GenerateMDSMatrix returns the MDS matrix for a given size
 func GenerateMDSMatrix(t int) [][]fr.Element {
 	result := make([][]fr.Element, t)
@@ -0,0 +1,292 @@
+package hash
+
+import (
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+)
+
+// Poseidon2T2 is a Poseidon2 hasher with t = 2
+var Poseidon2T2 Poseidon2Hasher
+
+// Poseidon2T3 is a Poseidon2 hasher with t = 3
+var Poseidon2T3 Poseidon2Hasher
+
+// Poseidon2T4 is a Poseidon2 hasher with t = 4. It is a non-standard instance, see `initPoseidon2`
+var Poseidon2T4 Poseidon2Hasher
+
+// Poseidon2T8 is a Poseidon2 hasher with t = 8. It is a non-standard instance, see `initPoseidon2`
+var Poseidon2T8 Poseidon2Hasher
+
+// The parameters are set according to https://eprint.iacr.org/2023/323.pdf
+// and its reference implementation https://github.com/HorizenLabs/poseidon2.
+//
+// The numbers of rounds and the round constants are those of the reference scripts, for
+// x^5 and 128 bits of security. The internal matrices of t = 2 and t = 3 are the fixed ones
+// of the paper, and t = 3 matches the BN254 instance of the reference.
+//
+// T4 and T8 are NON-STANDARD : the reference samples their internal matrices at random and
+// publishes none for BN254, so their outputs do not match any other implementation. Their
+// diagonals are the smallest distinct integers for which `M_I^k` has an irreducible minimal
+// polynomial of degree `t` for k = 1, ..., 2t, which is the criterion of the reference.
+// `TestPoseidon2InternalMatrices` checks it.
+func initPoseidon2() {
+	Poseidon2T2 = newPoseidon2Hasher(2, 8, 56, []uint64{1, 2})
+	Poseidon2T3 = newPoseidon2Hasher(3, 8, 56, []uint64{1, 1, 2})
+	Poseidon2T4 = newPoseidon2Hasher(4, 8, 56, []uint64{1, 3, 5, 7})
+	Poseidon2T8 = newPoseidon2Hasher(8, 8, 57, []uint64{1, 2, 4, 5, 6, 7, 8, 9})
+}
+
+// Poseidon2Hasher contains all the parameters to specify a Poseidon2 permutation
+type Poseidon2Hasher struct {
+	t        int
+	nRoundsF int // number of full rounds at the beginning, and at the end
+	nRoundsP int
+	// One constant per entry of the state in full rounds, a single one in partial rounds
+	arks [][]fr.Element
+	// The internal matrix is the all-ones matrix plus `diag(diag)`
+	diag []fr.Element
+}
+
+// newPoseidon2Hasher generates the parameters to run Poseidon2, `nRoundsF` is the total number of full rounds
+func newPoseidon2Hasher(t, nRoundsF, nRoundsP int, diag []uint64) Poseidon2Hasher {
+	p := Poseidon2Hasher{
+		t:        t,
+		nRoundsF: nRoundsF / 2,
+		nRoundsP: nRoundsP,
+		arks:     make([][]fr.Element, nRoundsF+nRoundsP),
+		diag:     make([]fr.Element, t),
+	}
+
+	for k := range diag {
+		p.diag[k].SetUint64(diag[k])
+	}
+
+	grain := newGrainLFSR(t, nRoundsF, nRoundsP)
+	for i := range p.arks {
+		n := 1
+		if p.isFull(i) {
+			n = t
+		}
+		p.arks[i] = make([]fr.Element, n)
+		for k := range p.arks[i] {
+			p.arks[i][k] = grain.fieldElement()
+		}
+	}
+
+	return p
+}
+
+// isFull returns true if the i-th round is a full round
+func (p *Poseidon2Hasher) isFull(i int) bool {
+	return i < p.nRoundsF || i >= p.nRoundsF+p.nRoundsP
+}
+
+// Permutation applies the Poseidon2 permutation to the state, in place
+func (p *Poseidon2Hasher) Permutation(state []fr.Element) {
+	p.externalMatrixInplace(state)
+
+	for i := range p.arks {
+		if p.isFull(i) {
+			for k := range state {
+				state[k].Add(&state[k], &p.arks[i][k])
+				PowInplace(&state[k], 5)
+			}
+			p.externalMatrixInplace(state)
+			continue
+		}
+		state[0].Add(&state[0], &p.arks[i][0])
+		PowInplace(&state[0], 5)
+		p.internalMatrixInplace(state)
+	}
+}
+
+// Hash hashes a full message with a sponge, whose capacity is the first entry of the state and the
+// rate the `t - 1` others. The capacity is initialized with the length of the message, so that the
+// zero-padding of the last block is unambiguous. For t = 2, this makes the rate a single element.
+func (p *Poseidon2Hasher) Hash(msg []fr.Element) fr.Element {
+	state := make([]fr.Element, p.t)
+	state[0].SetUint64(uint64(len(msg)))
+
+	rate := p.t - 1
+	for i := 0; i < len(msg); i += rate {
+		for j := 0; j < rate && i+j < len(msg); j++ {
+			state[j+1].Add(&state[j+1], &msg[i+j])
+		}
+		p.Permutation(state)
+	}
+
+	// Not even one permutation on an empty message
+	if len(msg) == 0 {
+		p.Permutation(state)
+	}
+
+	return state[1]
+}
+
+// externalMatrixInplace multiplies the state by the matrix of the full rounds. It is `circ(2, 1)` and
+// `circ(2, 1, 1)` for t = 2 and 3, M4 for t = 4 and `circ(2 * M4, M4)` for t = 8, where M4 is
+//
+//	5 7 1 3
+//	4 6 1 1
+//	1 3 5 7
+//	1 1 4 6
+func (p *Poseidon2Hasher) externalMatrixInplace(state []fr.Element) {
+	if p.t < 4 {
+		var sum fr.Element
+		for k := range state {
+			sum.Add(&sum, &state[k])
+		}
+		for k := range state {
+			state[k].Add(&state[k], &sum)
+		}
+		return
+	}
+
+	for j := 0; j < p.t; j += 4 {
+		m4Inplace(state[j : j+4])
+	}
+	if p.t == 4 {
+		return
+	}
+
+	var sums [4]fr.Element
+	for k := range state {
+		sums[k%4].Add(&sums[k%4], &state[k])
+	}
+	for k := range state {
+		state[k].Add(&state[k], &sums[k%4])
+	}
+}
+
+// m4Inplace multiplies a block of 4 entries by M4, with the addition chain of the paper
+func m4Inplace(x []fr.Element) {
+	var t0, t1, t2, t3, t4, t5, t6, t7 fr.Element
+	t0.Add(&x[0], &x[1])
+	t1.Add(&x[2], &x[3])
+	t2.Double(&x[1]).Add(&t2, &t1)
+	t3.Double(&x[3]).Add(&t3, &t0)
+	t4.Double(&t1).Double(&t4).Add(&t4, &t3)
+	t5.Double(&t0).Double(&t5).Add(&t5, &t2)
+	t6.Add(&t3, &t5)
+	t7.Add(&t2, &t4)
+	x[0], x[1], x[2], x[3] = t6, t5, t7, t4
+}
+
+// internalMatrixInplace multiplies the state by the matrix of the partial rounds, the all-ones matrix plus `diag(diag)`
+func (p *Poseidon2Hasher) internalMatrixInplace(state []fr.Element) {
+	var sum fr.Element
+	for k := range state {
+		sum.Add(&sum, &state[k])
+	}
+	for k := range state {
+		state[k].Mul(&state[k], &p.diag[k])
+		state[k].Add(&state[k], &sum)
+	}
+}
+
+// T returns the width of the state
+func (p *Poseidon2Hasher) T() int { return p.t }
+
+// NbRounds returns the number of full rounds at the beginning and at the end of the permutation,
+// and the number of partial rounds in the middle
+func (p *Poseidon2Hasher) NbRounds() (nRoundsF, nRoundsP int) { return p.nRoundsF, p.nRoundsP }
+
+// Arks returns the round constants. There are `t` of them in full rounds, and one in partial rounds
+// as only the first entry of the state is updated.
+func (p *Poseidon2Hasher) Arks() [][]fr.Element { return p.arks }
+
+// ExternalMatrix returns the matrix of the full rounds, it is also applied before the first round
+func (p *Poseidon2Hasher) ExternalMatrix() [][]fr.Element {
+	return p.matrixOf(p.externalMatrixInplace)
+}
+
+// InternalMatrix returns the matrix of the partial rounds
+func (p *Poseidon2Hasher) InternalMatrix() [][]fr.Element {
+	return p.matrixOf(p.internalMatrixInplace)
+}
+
+// matrixOf returns the matrix of a linear map, by applying it to the canonical basis
+func (p *Poseidon2Hasher) matrixOf(apply func(state []fr.Element)) [][]fr.Element {
+	res := make([][]fr.Element, p.t)
+	for i := range res {
+		res[i] = make([]fr.Element, p.t)
+	}
+	for j := 0; j < p.t; j++ {
+		column := make([]fr.Element, p.t)
+		column[j].SetOne()
+		apply(column)
+		for i := range res {
+			res[i][j] = column[i]
+		}
+	}
+	return res
+}
+
+// grainLFSR is the pseudo-random generator used by Poseidon and Poseidon2 to derive their round constants
+type grainLFSR struct {
+	state [80]bool
+}
+
+// newGrainLFSR initializes the generator for a prime field, the S-box x^5, and the given parameters
+func newGrainLFSR(t, nRoundsF, nRoundsP int) *grainLFSR {
+	g := &grainLFSR{}
+	pos := 0
+	write := func(v, nBits int) {
+		for i := nBits - 1; i >= 0; i-- {
+			g.state[pos] = (v>>i)&1 == 1
+			pos++
+		}
+	}
+	write(1, 2) // prime field
+	write(0, 4) // x^alpha S-box
+	write(fr.Bits, 12)
+	write(t, 12)
+	write(nRoundsF, 10)
+	write(nRoundsP, 10)
+	write(1<<30-1, 30)
+
+	// Discards the first 160 bits
+	for i := 0; i < 160; i++ {
+		g.next()
+	}
+	return g
+}
+
+// next clocks the LFSR once and returns the new bit
+func (g *grainLFSR) next() bool {
+	s := &g.state
+	b := s[62] != s[51] != s[38] != s[23] != s[13] != s[0]
+	copy(s[:], s[1:])
+	s[79] = b
+	return b
+}
+
+// bit returns the next output bit, with the self-shrinking of the generator : the bits come in pairs,
+// and the second one is output only if the first one is set.
+func (g *grainLFSR) bit() bool {
+	for !g.next() {
+		g.next()
+	}
+	return g.next()
+}
+
+// fieldElement samples field elements from `fr.Bits` bits, big-endian, until one is smaller than the modulus
+func (g *grainLFSR) fieldElement() fr.Element {
+	var v, one big.Int
+	one.SetUint64(1)
+	for {
+		v.SetUint64(0)
+		for i := 0; i < fr.Bits; i++ {
+			v.Lsh(&v, 1)
+			if g.bit() {
+				v.Or(&v, &one)
+			}
+		}
+		if v.Cmp(fr.Modulus()) < 0 {
+			var res fr.Element
+			res.SetBigInt(&v)
+			return res
+		}
+	}
+}
@@ -18,11 +18,12 @@ func GetLagrangePolynomial(domainSize int) [][]fr.Element {
 
 var lagrangePolynomials [][][]fr.Element
 
-const maxDomainSize int = 12
+// MaxDomainSize is the largest domain for which the lagrange polynomials are precomputed
+const MaxDomainSize int = 12
 
 func initLagrangePolynomials() {
-	lagrangePolynomials = make([][][]fr.Element, maxDomainSize+1)
-	for i := 0; i < maxDomainSize+1; i++ {
+	lagrangePolynomials = make([][][]fr.Element, MaxDomainSize+1)
+	for i := 0; i < MaxDomainSize+1; i++ {
 		lagrangePolynomials[i] = LagrangeCoefficient(i)
 	}
 }
@@ -45,3 +45,17 @@ func (c *Circuit) Assign() {
 
 // Options for the `Circuit` constructor
 type GkrOption func(c *Circuit)
+
+// WithPoseidon makes the wrapped circuit use a gadget proving Poseidon updates, see `NewPoseidonGkrGadget`
+func WithPoseidon(t int) GkrOption {
+	return func(c *Circuit) {
+		c.Gadget = *NewPoseidonGkrGadget(t)
+	}
+}
+
+// WithMimcSponge makes the wrapped circuit use a gadget proving MiMCSponge permutations, see `NewMimcSpongeGkrGadget`
+func WithMimcSponge() GkrOption {
+	return func(c *Circuit) {
+		c.Gadget = *NewMimcSpongeGkrGadget()
+	}
+}
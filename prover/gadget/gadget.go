@@ -16,20 +16,9 @@ const GKR_MIMC_GET_HASH_HINT_ID hint.ID = hint.ID(780000001)
 const GKR_MIMC_GET_INITIAL_RANDOMNESS_HINT_ID hint.ID = hint.ID(780000002)
 const GKR_MIMC_GKR_PROVER_HINT_ID hint.ID = hint.ID(780000003)
 
-// Caches the result of the `UpdateMimcHash(0, 0)`
-// For padding
-var hashOfZeroes fr.Element
-
 // Default chunkSize used by GKR
 const DEFAULT_CHUNK_SIZE int = 1024
 
-func init() {
-	var zero fr.Element
-	// Since it's the hash of zero, we don't need to the "newState = gkrOutput + block + state" thing
-	// We have the equality
-	hash.MimcUpdateInplace(&hashOfZeroes, zero)
-}
-
 // Helper for performing hashes using GKR
 type GkrGadget struct {
 	// Pointers to variables that must have been allocated somewhere else
@@ -37,6 +26,8 @@ type GkrGadget struct {
 	ioStore           IoStore           `gnark:"-"`
 
 	Circuit circuit.Circuit `gnark:"-"`
+	// Computes the outputs of the circuit from its inputs, for one instance
+	native func(inputs []fr.Element) []fr.Element `gnark:"-"`
 
 	r1cs  *R1CS  `gnark:"-"`
 	proof *Proof `gnark:"-"`
@@ -51,17 +42,61 @@ func NewGkrGadget() *GkrGadget {
 	return &GkrGadget{
 		ioStore: NewIoStore(&mimc, 16),
 		Circuit: mimc,
+		native: func(inputs []fr.Element) []fr.Element {
+			// The circuit only computes the permutation, the state is its key
+			return []fr.Element{hash.MimcKeyedPermutation(inputs[1], inputs[0])}
+		},
+	}
+}
+
+// NewPoseidonGkrGadget returns a gadget proving the updates of `hash.PoseidonHasher` with a state
+// of width `t` (2, 4 or 8) using `examples.PoseidonCircuit`, see `UpdatePoseidon`
+func NewPoseidonGkrGadget(t int) *GkrGadget {
+	poseidon := examples.PoseidonCircuit(t)
+	hasher := examples.PoseidonHasher(t)
+
+	return &GkrGadget{
+		ioStore: NewIoStore(&poseidon, 16),
+		Circuit: poseidon,
+		native: func(inputs []fr.Element) []fr.Element {
+			state := append([]fr.Element{}, inputs[:t]...)
+			hasher.Update(state, inputs[t:])
+			return state
+		},
+	}
+}
+
+// NewMimcSpongeGkrGadget returns a gadget proving the permutations of circomlib's MiMCSponge,
+// `hash.MimcSpongePermutation`, using `examples.MimcSpongeCircuit`, see `MimcSpongePermutation`
+func NewMimcSpongeGkrGadget() *GkrGadget {
+	sponge := examples.MimcSpongeCircuit()
+
+	return &GkrGadget{
+		ioStore: NewIoStore(&sponge, 16),
+		Circuit: sponge,
+		native: func(inputs []fr.Element) []fr.Element {
+			xL, xR := hash.MimcSpongePermutation(inputs[0], inputs[1], inputs[2])
+			return []fr.Element{xL, xR}
+		},
 	}
 }
 
 // Used for padding dummy values. It adds constants everywhere so the result is not return
 // (as it is basically useless)
 func (g *GkrGadget) updateHasherWithZeroes(cs frontend.API) {
-	g.ioStore.Push(
-		cs,
-		[]frontend.Variable{frontend.Variable(0), frontend.Variable(0)},
-		hashOfZeroes,
-	)
+	zeroes := make([]fr.Element, g.Circuit.InputArity())
+	outputs := g.native(zeroes)
+
+	inputVars := make([]frontend.Variable, len(zeroes))
+	for i := range inputVars {
+		inputVars[i] = frontend.Variable(0)
+	}
+	outputVars := make([]frontend.Variable, len(outputs))
+	for i := range outputs {
+		outputVars[i] = outputs[i]
+	}
+
+	g.ioStore.Push(cs, inputVars, outputVars)
 }
 
 func (g *GkrGadget) getInitialRandomness(cs frontend.API) (initialRandomness frontend.Variable, qPrime []frontend.Variable) {
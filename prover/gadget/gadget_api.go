@@ -22,8 +22,48 @@ func (g *GkrGadget) UpdateHasher(
 	g.ioStore.Push(
 		cs,
 		[]frontend.Variable{state, msg},
-		output[0],
+		[]frontend.Variable{output[0]},
 	)
 
 	return cs.Add(output[0], state, state, msg)
 }
+
+// UpdatePoseidon passes the update of a Poseidon hasher to GKR, see `NewPoseidonGkrGadget`.
+// It returns the updated state, as `hash.PoseidonHasher.Update` does.
+func (g *GkrGadget) UpdatePoseidon(
+	cs frontend.API,
+	state []frontend.Variable,
+	block []frontend.Variable,
+) []frontend.Variable {
+
+	// The circuit computes the whole update, including the Miyaguchi-Preneel feed-forward
+	output, err := cs.NewHint(g.HashHint(), append(append([]frontend.Variable{}, state...), block...)...)
+	common.Assert(err == nil, "Unexpected error")
+
+	g.ioStore.Push(
+		cs,
+		append(append([]frontend.Variable{}, state...), block...),
+		output,
+	)
+
+	return output
+}
+
+// MimcSpongePermutation passes a permutation of circomlib's MiMCSponge to GKR, see `NewMimcSpongeGkrGadget`.
+// It returns the permuted `xL` and `xR`, as `hash.MimcSpongePermutation` does.
+func (g *GkrGadget) MimcSpongePermutation(
+	cs frontend.API,
+	xL, xR, k frontend.Variable,
+) (frontend.Variable, frontend.Variable) {
+
+	output, err := cs.NewHint(g.HashHint(), xL, xR, k)
+	common.Assert(err == nil, "Unexpected error")
+
+	g.ioStore.Push(
+		cs,
+		[]frontend.Variable{xL, xR, k},
+		output,
+	)
+
+	return output[0], output[1]
+}
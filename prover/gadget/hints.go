@@ -6,9 +6,9 @@ import (
 
 	"github.com/AlexandreBelling/gnark/backend/hint"
 	"github.com/AlexandreBelling/gnark/frontend"
+	"github.com/consensys/gkr-mimc/circuit"
 	"github.com/consensys/gkr-mimc/common"
 	gkrNative "github.com/consensys/gkr-mimc/gkr"
-	"github.com/consensys/gkr-mimc/hash"
 	"github.com/consensys/gkr-mimc/poly"
 	"github.com/consensys/gkr-mimc/snark/gkr"
 	"github.com/consensys/gnark-crypto/ecc"
@@ -61,9 +61,9 @@ func (h *GkrProverHint) UUID() hint.ID {
 	return 13135755
 }
 
-// NbOutputs of the hash hint
+// NbOutputs of the hash hint, one per output of the circuit
 func (h *HashHint) NbOutputs(_ ecc.ID, nbInput int) int {
-	return 1
+	return h.g.Circuit.OutputArity()
 }
 
 // NbOutputs of the initial randomness hint
@@ -80,39 +80,41 @@ func (h *GkrProverHint) NbOutputs(_ ecc.ID, nbInput int) int {
 	// Iteratively finds the bN of the circuit from the input size
 	// Can't guarantee that g.ioStore.index contains the right values
 	bN := 0
-	inputArity := circuit.InputArity()
-	for {
-		inputSize := (1<<bN)*(inputArity+1) + bN
-		if inputSize == nbInput {
-			break
+	for ; ; bN++ {
+		// sanity check in case something must be wrong with the formula : the input layers alone are too large
+		if lowerBound := (1<<bN)*circuit.InputArity() + bN; lowerBound > nbInput {
+			panic(fmt.Sprintf("Took over the size: %v > %v. Something wrong with the formula for the size", lowerBound, nbInput))
 		}
-		// sanity check in case something must be wrong with the formula
-		if inputSize > nbInput {
-			panic(fmt.Sprintf("Took over the size: %v > %v. Something wrong with the formula for the size", inputSize, nbInput))
+		// The circuit may be invalid for small bN, e.g. its fixed layers are larger than the inputs
+		inputSize, err := gkrProverHintInputSize(circuit, bN)
+		if err == nil && inputSize == nbInput {
+			break
 		}
-		// try with a larger bn
-		bN += 1
 	}
 
-	sumcheckSize := 0
-	claimsSize := 0
-	qPrimeSize := 0
-
-	for _, layer := range circuit {
-		// If not an input gate, adds the sumchecks
-		if layer.Gate != nil {
-			// The degree is deg + 1 (because of the multiplication by `eq(h, q)`)
-			// So the number of coefficients is deg + 2
-			nbCoeffs := layer.Gate.Degree() + 2
-			sumcheckSize += bN * nbCoeffs // size of the sumcheck for the layer
-		}
-
-		claimsSize += len(layer.Out)
-		qPrimeSize += bN * len(layer.Out)
+	proofSize, err := circuit.ProofSize(bN)
+	if err != nil {
+		panic(err)
 	}
+	return proofSize
+}
 
-	qPrimeSize += bN // For the output layer
-	return sumcheckSize + claimsSize + qPrimeSize
+// gkrProverHintInputSize returns the number of inputs of the gkr prover hint : qPrime, then
+// the values of the input layers and of the output layers. The output layers do not
+// necessarily have as many values as the input layers, see `circuit.Layer.Size`.
+func gkrProverHintInputSize(c circuit.Circuit, bN int) (int, error) {
+	sizes, err := c.Sizes(1 << bN)
+	if err != nil {
+		return 0, err
+	}
+	res := bN
+	for l := 0; l < c.InputArity(); l++ {
+		res += sizes[l]
+	}
+	for _, o := range c.OutputLayers() {
+		res += sizes[o]
+	}
+	return res, nil
 }
 
 // String of the hash hint
@@ -133,13 +135,16 @@ func (h *GkrProverHint) String() string {
 // Returns the Hint functions that can help gnark's solver figure out that
 // the output of the GKR should be a hash
 func (h *HashHint) Call(curve ecc.ID, inps []*big.Int, outputs []*big.Int) error {
-	var state, block fr.Element
-	state.SetBigInt(inps[0])
-	block.SetBigInt(inps[1])
+	inputs := make([]fr.Element, len(inps))
+	for i := range inps {
+		inputs[i].SetBigInt(inps[i])
+	}
 
 	// Properly computes the hash
-	hashed := hash.MimcKeyedPermutation(block, state)
-	hashed.ToBigIntRegular(outputs[0])
+	hashed := h.g.native(inputs)
+	for i := range hashed {
+		hashed[i].ToBigIntRegular(outputs[i])
+	}
 	h.g.ioStore.index++
 	return nil
 }
@@ -210,14 +215,26 @@ func (h *GkrProverHint) Call(_ ecc.ID, inputsBI []*big.Int, oups []*big.Int) err
 	for i := range inputs {
 		inputs[i], drain = drain[:paddedIndex], drain[paddedIndex:]
 	}
-	// The output: here is passed to force the solver to wait for all the output
-	outputs, drain := drain[:paddedIndex], drain[paddedIndex:]
+	// The outputs: here are passed to force the solver to wait for all the outputs
+	sizes, err := h.g.Circuit.Sizes(paddedIndex)
+	if err != nil {
+		return err
+	}
+	outputs := make([]poly.MultiLin, h.g.Circuit.OutputArity())
+	for i, o := range h.g.Circuit.OutputLayers() {
+		outputs[i], drain = drain[:sizes[o]], drain[sizes[o]:]
+	}
 
 	// Sanity check
 	common.Assert(len(drain) == 0, "The drain was expected to emptied but there remains %v elements", len(drain))
 
 	// Runs the actual prover
 	assignment := h.g.Circuit.Assign(inputs...)
+	if debug {
+		// For debug : only -> Check the assignment before it gets folded by the prover
+		err := assignment.Check(h.g.Circuit, circuit.DumpInputs())
+		common.Assert(err == nil, "GKR assignment is wrong - %v", err)
+	}
 	t := common.NewTimer("gkr prover hint")
 	gkrProof := gkrNative.Prove(h.g.Circuit, assignment, qPrime)
 	t.Close()
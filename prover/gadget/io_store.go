@@ -16,27 +16,31 @@ type IoStore struct {
 	inputs            []polynomial.MultiLin // The variables as Gkr inputs
 	inputsVarIds      [][]int               // The variable IDs as Gkr outputs
 	inputsIsConstant  [][]bool              // True if the variable is a constant
-	outputs           polynomial.MultiLin   // The variables as Gkr outputs
-	outputsVarIds     []int                 // The ids of the variable as Gkr outputs
-	outputsIsConstant []bool                // True if the variable is a constant
+	outputs           []polynomial.MultiLin // The variables as Gkr outputs
+	outputsVarIds     [][]int               // The ids of the variable as Gkr outputs
+	outputsIsConstant [][]bool              // True if the variable is a constant
 	allocEpoch        int
 	index             int
 	inputArity        int
-	// Our implementation only support an arity of 1 for the outputs
+	outputArity       int
 }
 
 // Creates a new ioStore for the given circuit
 func NewIoStore(circuit *circuit.Circuit, allocEpoch int) IoStore {
 
 	inputArity := circuit.InputArity()
+	outputArity := circuit.OutputArity()
 
 	return IoStore{
-		inputs:           make([]polynomial.MultiLin, circuit.InputArity()),
-		inputsVarIds:     make([][]int, inputArity),
-		inputsIsConstant: make([][]bool, inputArity),
-		outputs:          polynomial.MultiLin{},
-		allocEpoch:       allocEpoch,
-		inputArity:       circuit.InputArity(),
+		inputs:            make([]polynomial.MultiLin, inputArity),
+		inputsVarIds:      make([][]int, inputArity),
+		inputsIsConstant:  make([][]bool, inputArity),
+		outputs:           make([]polynomial.MultiLin, outputArity),
+		outputsVarIds:     make([][]int, outputArity),
+		outputsIsConstant: make([][]bool, outputArity),
+		allocEpoch:        allocEpoch,
+		inputArity:        inputArity,
+		outputArity:       outputArity,
 	}
 }
 
@@ -45,13 +49,13 @@ func (io *IoStore) Index() int {
 	return io.index
 }
 
-// Add an element in the ioStack
-func (io *IoStore) Push(cs frontend.API, inputs []frontend.Variable, output frontend.Variable) {
+// Add an element in the ioStack. The outputs are given in the order of `circuit.OutputLayers()`
+func (io *IoStore) Push(cs frontend.API, inputs []frontend.Variable, outputs []frontend.Variable) {
 
 	// Check that the dimension of the provided arrays is consistent with what was expected
-	if len(inputs) != io.inputArity {
-		panic(fmt.Sprintf("Expected inputs/outputs to have size %v but got %v",
-			io.inputArity, len(inputs),
+	if len(inputs) != io.inputArity || len(outputs) != io.outputArity {
+		panic(fmt.Sprintf("Expected inputs/outputs to have size %v/%v but got %v/%v",
+			io.inputArity, io.outputArity, len(inputs), len(outputs),
 		))
 	}
 
@@ -60,15 +64,14 @@ func (io *IoStore) Push(cs frontend.API, inputs []frontend.Variable, output fron
 		inputs[i] = cs.EnforceWire(inputs[i])
 	}
 
-	// And the output...
-	output = cs.EnforceWire(output)
+	// And the outputs...
+	for i := range outputs {
+		outputs[i] = cs.EnforceWire(outputs[i])
+	}
 
 	// Performs an allocation if necessary
 	io.allocateForOneMore()
 
-	common.Assert(len(inputs) == 2, "Expected 2 inputs, got %v", len(inputs))
-	common.Assert(len(io.inputs) == 2, "There should be 2 inputs, but got %v", len(io.inputs))
-
 	// Append the inputs
 	for i := range inputs {
 		wire := inputs[i]
@@ -78,12 +81,14 @@ func (io *IoStore) Push(cs frontend.API, inputs []frontend.Variable, output fron
 		io.inputsIsConstant[i] = append(io.inputsIsConstant[i], wireConstant)
 	}
 
-	// Append the output
-	wire := output
-	wireID, wireConstant := cs.WireId(wire)
-	io.outputs = append(io.outputs, wire)
-	io.outputsVarIds = append(io.outputsVarIds, wireID)
-	io.outputsIsConstant = append(io.outputsIsConstant, wireConstant)
+	// Append the outputs
+	for i := range outputs {
+		wire := outputs[i]
+		wireID, wireConstant := cs.WireId(wire)
+		io.outputs[i] = append(io.outputs[i], wire)
+		io.outputsVarIds[i] = append(io.outputsVarIds[i], wireID)
+		io.outputsIsConstant[i] = append(io.outputsIsConstant[i], wireConstant)
+	}
 
 	io.index++
 }
@@ -93,7 +98,7 @@ func (io *IoStore) Push(cs frontend.API, inputs []frontend.Variable, output fron
 func (io *IoStore) DumpForProverMultiExp() []frontend.Variable {
 
 	// Allocate the result
-	resSize := io.index * (io.inputArity + 1)
+	resSize := io.index * (io.inputArity + io.outputArity)
 	res := make([]frontend.Variable, 0, resSize)
 
 	// Sanity checks
@@ -104,7 +109,9 @@ func (io *IoStore) DumpForProverMultiExp() []frontend.Variable {
 		res = append(res, io.inputs[i]...)
 	}
 
-	res = append(res, io.outputs...)
+	for i := range io.outputs {
+		res = append(res, io.outputs[i]...)
+	}
 
 	return res
 }
@@ -117,7 +124,7 @@ func (io *IoStore) DumpForProverMultiExp() []frontend.Variable {
 func (io *IoStore) DumpForGkrProver(qPrimeArg []frontend.Variable) []frontend.Variable {
 
 	// Allocate the result
-	nInputs, nOutputs, bN := len(io.inputs[0])*io.inputArity, len(io.outputs), len(qPrimeArg)
+	nInputs, nOutputs, bN := len(io.inputs[0])*io.inputArity, len(io.outputs[0])*io.outputArity, len(qPrimeArg)
 	resSize := nInputs + nOutputs + bN
 	res := make([]frontend.Variable, 0, resSize)
 
@@ -130,7 +137,9 @@ func (io *IoStore) DumpForGkrProver(qPrimeArg []frontend.Variable) []frontend.Va
 	for i := range io.inputs {
 		res = append(res, io.inputs[i]...)
 	}
-	res = append(res, io.outputs...)
+	for i := range io.outputs {
+		res = append(res, io.outputs[i]...)
+	}
 
 	return res
 }
@@ -141,27 +150,31 @@ func (io *IoStore) InputsForVerifier() []polynomial.MultiLin {
 }
 
 // Returns the gkr outputs in the correct order to be processed by the verifier
-func (io *IoStore) OutputsForVerifier() polynomial.MultiLin {
+func (io *IoStore) OutputsForVerifier() []polynomial.MultiLin {
 	return io.outputs
 }
 
 // Returns all the varIds in a single vec (no deduplication)
 func (io *IoStore) VarIds() []int {
-	res := make([]int, 0, io.index*(io.inputArity+1))
+	res := make([]int, 0, io.index*(io.inputArity+io.outputArity))
 	for i := range io.inputsVarIds {
 		res = append(res, io.inputsVarIds[i]...)
 	}
-	res = append(res, io.outputsVarIds...)
+	for i := range io.outputsVarIds {
+		res = append(res, io.outputsVarIds[i]...)
+	}
 	return res
 }
 
 // Returns all the `isConstant` concatenated in a single vec
 func (io *IoStore) VarAreConstant() []bool {
-	res := make([]bool, 0, io.index*(io.inputArity+1))
+	res := make([]bool, 0, io.index*(io.inputArity+io.outputArity))
 	for i := range io.inputsIsConstant {
 		res = append(res, io.inputsIsConstant[i]...)
 	}
-	res = append(res, io.outputsIsConstant...)
+	for i := range io.outputsIsConstant {
+		res = append(res, io.outputsIsConstant[i]...)
+	}
 	return res
 }
 
@@ -179,9 +192,11 @@ func (io *IoStore) allocateForOneMore() {
 		}
 
 		incOutputs := io.index
-		io.outputs = IncreaseCapVariable(io.outputs, incOutputs)
-		io.outputsVarIds = IncreaseCapInts(io.outputsVarIds, incOutputs)
-		io.outputsIsConstant = IncreaseCapBools(io.outputsIsConstant, incOutputs)
+		for i := range io.outputs {
+			io.outputs[i] = IncreaseCapVariable(io.outputs[i], incOutputs)
+			io.outputsVarIds[i] = IncreaseCapInts(io.outputsVarIds[i], incOutputs)
+			io.outputsIsConstant[i] = IncreaseCapBools(io.outputsIsConstant[i], incOutputs)
+		}
 
 		io.allocEpoch *= 2
 	}
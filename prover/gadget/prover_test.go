@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/AlexandreBelling/gnark/frontend"
+	"github.com/consensys/gkr-mimc/examples"
 	"github.com/consensys/gkr-mimc/hash"
 	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
 	"github.com/stretchr/testify/assert"
@@ -77,3 +78,136 @@ func TestFullProver(t *testing.T) {
 	err = Verify(proof, &vk, []fr.Element{})
 	assert.NoError(t, err)
 }
+
+// Circuit performing a few Poseidon updates
+type TestPoseidonGadgetCircuit struct {
+	States [][]frontend.Variable
+	Blocks [][]frontend.Variable
+	Hashes [][]frontend.Variable
+}
+
+// Allocate the Poseidon test gadget, for a state of width `t`
+func AllocateTestPoseidonGadgetCircuit(n, t int) TestPoseidonGadgetCircuit {
+	res := TestPoseidonGadgetCircuit{
+		States: make([][]frontend.Variable, n),
+		Blocks: make([][]frontend.Variable, n),
+		Hashes: make([][]frontend.Variable, n),
+	}
+	for i := 0; i < n; i++ {
+		res.States[i] = make([]frontend.Variable, t)
+		res.Blocks[i] = make([]frontend.Variable, t)
+		res.Hashes[i] = make([]frontend.Variable, t)
+	}
+	return res
+}
+
+func (t *TestPoseidonGadgetCircuit) Define(cs frontend.API, gadget *GkrGadget) error {
+	for i := range t.States {
+		y := gadget.UpdatePoseidon(cs, t.States[i], t.Blocks[i])
+		for j := range y {
+			cs.AssertIsEqual(t.Hashes[i][j], y[j])
+		}
+	}
+	return nil
+}
+
+func TestFullProverPoseidon(t *testing.T) {
+	n := 5
+
+	for _, width := range []int{2, 4, 8} {
+		innerCircuit := AllocateTestPoseidonGadgetCircuit(n, width)
+		innerAssignment := AllocateTestPoseidonGadgetCircuit(n, width)
+		for i := 0; i < n; i++ {
+			state := make([]fr.Element, width)
+			block := make([]fr.Element, width)
+			for j := range block {
+				state[j].SetUint64(uint64(i))
+				block[j].SetUint64(uint64(i + j))
+				innerAssignment.States[i][j] = state[j]
+				innerAssignment.Blocks[i][j] = block[j]
+			}
+			examples.PoseidonHasher(width).Update(state, block)
+			for j := range state {
+				innerAssignment.Hashes[i][j] = state[j]
+			}
+		}
+
+		circuit := WrapCircuitUsingGkr(&innerCircuit, WithPoseidon(width))
+
+		r1cs, err := circuit.Compile()
+		assert.NoError(t, err)
+
+		pk, vk, err := Setup(&r1cs)
+		assert.NoError(t, err, "Error during the setup")
+
+		assignment := WrapCircuitUsingGkr(&innerAssignment, WithPoseidon(width))
+		assignment.Assign()
+
+		solution, err := assignment.Solve(r1cs)
+		assert.NoError(t, err)
+
+		proof, err := ComputeProof(&r1cs, &pk, solution, assignment.Gadget.proof)
+		assert.NoError(t, err)
+
+		assert.NoError(t, Verify(proof, &vk, []fr.Element{}), "t = %v", width)
+	}
+}
+
+// Circuit hashing pairs of elements with MiMCSponge, as the Merkle trees of Tornado Cash do
+type TestMimcSpongeGadgetCircuit struct {
+	Lefts  []frontend.Variable
+	Rights []frontend.Variable
+	Hashes []frontend.Variable
+}
+
+// Allocate the MiMCSponge test gadget
+func AllocateTestMimcSpongeGadgetCircuit(n int) TestMimcSpongeGadgetCircuit {
+	return TestMimcSpongeGadgetCircuit{
+		Lefts:  make([]frontend.Variable, n),
+		Rights: make([]frontend.Variable, n),
+		Hashes: make([]frontend.Variable, n),
+	}
+}
+
+func (t *TestMimcSpongeGadgetCircuit) Define(cs frontend.API, gadget *GkrGadget) error {
+	for i := range t.Lefts {
+		xL, xR := gadget.MimcSpongePermutation(cs, t.Lefts[i], 0, 0)
+		xL, _ = gadget.MimcSpongePermutation(cs, cs.Add(xL, t.Rights[i]), xR, 0)
+		cs.AssertIsEqual(t.Hashes[i], xL)
+	}
+	return nil
+}
+
+func TestFullProverMimcSponge(t *testing.T) {
+	n := 5
+
+	innerCircuit := AllocateTestMimcSpongeGadgetCircuit(n)
+	innerAssignment := AllocateTestMimcSpongeGadgetCircuit(n)
+	for i := 0; i < n; i++ {
+		var l, r fr.Element
+		l.SetUint64(uint64(i))
+		r.SetUint64(uint64(2 * i))
+		innerAssignment.Lefts[i] = l
+		innerAssignment.Rights[i] = r
+		innerAssignment.Hashes[i] = hash.MimcSpongeHash(l, r)
+	}
+
+	circuit := WrapCircuitUsingGkr(&innerCircuit, WithMimcSponge())
+
+	r1cs, err := circuit.Compile()
+	assert.NoError(t, err)
+
+	pk, vk, err := Setup(&r1cs)
+	assert.NoError(t, err, "Error during the setup")
+
+	assignment := WrapCircuitUsingGkr(&innerAssignment, WithMimcSponge())
+	assignment.Assign()
+
+	solution, err := assignment.Solve(r1cs)
+	assert.NoError(t, err)
+
+	proof, err := ComputeProof(&r1cs, &pk, solution, assignment.Gadget.proof)
+	assert.NoError(t, err)
+
+	assert.NoError(t, Verify(proof, &vk, []fr.Element{}))
+}
@@ -0,0 +1,227 @@
+package gkr
+
+import (
+	"fmt"
+
+	"github.com/AlexandreBelling/gnark/backend"
+	// Registers the R1CS builder used to compile the gates
+	_ "github.com/AlexandreBelling/gnark/backend/groth16"
+	"github.com/AlexandreBelling/gnark/frontend"
+	"github.com/consensys/gkr-mimc/circuit"
+	"github.com/consensys/gkr-mimc/common"
+	"github.com/consensys/gkr-mimc/hash"
+	"github.com/consensys/gnark-crypto/ecc"
+)
+
+// Costs summarizes the costs of proving a circuit with GKR and of verifying the proof in a snark
+type Costs struct {
+	// Number of sumchecks run by the prover, one per non-input layer
+	NbSumchecks int
+	// Number of field elements in a `gkr.Proof`
+	ProofSize int
+	// Number of R1CS constraints added by the snark verifier of the proof
+	VerifierConstraints int
+}
+
+// String pretty-prints the costs
+func (c Costs) String() string {
+	return fmt.Sprintf(
+		"%v sumchecks, %v field elements in the proof, %v constraints for the verifier",
+		c.NbSumchecks, c.ProofSize, c.VerifierConstraints,
+	)
+}
+
+// mimcHashConstraints is the number of constraints to hash one field element in a snark
+const mimcHashConstraints int = 4 * hash.MimcRounds
+
+// CircuitCosts returns the costs of the circuit when its input layers have `2^bN` instances, without running it.
+// The number of constraints of each gate is found by compiling its `GnarkEval` on its own.
+func CircuitCosts(c circuit.Circuit, bN int) (Costs, error) {
+	bits, err := c.Bits(bN)
+	if err != nil {
+		return Costs{}, err
+	}
+
+	res := Costs{}
+	if res.ProofSize, err = c.ProofSize(bN); err != nil {
+		return Costs{}, err
+	}
+
+	gateConstraints := make(map[string]int)
+
+	// Evaluation of the output layers
+	for _, o := range c.OutputLayers() {
+		res.VerifierConstraints += bits[o] + multilinEvalConstraints(bits[o])
+	}
+	// The claims of the assertion layers are zero, only their qPrimes are checked
+	for _, o := range c.AssertionLayers() {
+		res.VerifierConstraints += bits[o]
+	}
+
+	for l := range c {
+		if c.IsInputLayer(l) {
+			// Evaluation of the input layer and final check
+			res.VerifierConstraints += multilinEvalConstraints(bits[l]) + 1
+			continue
+		}
+
+		if c.IsFixedLayer(l) {
+			// The verifier evaluates the fixed values itself for each claim, and checks them
+			fixedBits := common.Log2Ceil(len(c[l].Fixed))
+			res.VerifierConstraints += len(c[l].Out) * (fixedEvalConstraints(fixedBits) + 1)
+			continue
+		}
+
+		res.NbSumchecks++
+		gate := c[l].Gate
+
+		cost, ok := gateConstraints[gate.ID()]
+		if !ok {
+			if cost, err = GnarkEvalConstraints(gate, len(c[l].In)); err != nil {
+				return Costs{}, &circuit.LayerError{Layer: l, Kind: err, Details: fmt.Sprintf("could not compile the gate %v", gate.ID())}
+			}
+			gateConstraints[gate.ID()] = cost
+		}
+
+		inputBits := make([]int, len(c[l].In))
+		for k, inpL := range c[l].In {
+			inputBits[k] = bits[inpL]
+		}
+
+		// Output and assertion layers have a single claim : the one given by the verifier
+		nClaims := len(c[l].Out)
+		if c.IsOutputLayer(l) || c[l].Assertion {
+			nClaims = 1
+		}
+
+		var sumcheck, eq int
+		var eqIsConstant bool
+		if c[l].IsWired() {
+			sumcheck = sumcheckConstraints(nClaims, sum(inputBits...), 3)
+			eq, eqIsConstant = wiringPredicateConstraints(c[l].Wiring, bits[l], inputBits, nClaims)
+			// Consistency of the input qPrimes with the challenges
+			sumcheck += sum(inputBits...)
+		} else {
+			sumcheck = sumcheckConstraints(nClaims, bits[l], gate.Degree()+2)
+			eq, eqIsConstant = eqEvalConstraints(bits[l], nClaims)
+			sumcheck += bits[l] * len(c[l].In)
+		}
+
+		if c[l].Assertion {
+			// The claim is the constant zero : its hash is computed when compiling
+			sumcheck -= mimcHashConstraints
+		}
+
+		res.VerifierConstraints += sumcheck + cost + eq + 1 // final check
+		if !eqIsConstant {
+			// Multiplication of the gate by eq
+			res.VerifierConstraints++
+		}
+	}
+
+	return res, nil
+}
+
+// gnarkEvalCircuit calls `GnarkEval` on its inputs
+type gnarkEvalCircuit struct {
+	Inputs []frontend.Variable
+	Output frontend.Variable
+	gate   circuit.Gate
+}
+
+// Define declares the constraints of the gate
+func (g *gnarkEvalCircuit) Define(cs frontend.API) error {
+	cs.AssertIsEqual(g.gate.GnarkEval(cs, g.Inputs...), g.Output)
+	return nil
+}
+
+// GnarkEvalConstraints returns the number of R1CS constraints of `gate.GnarkEval` on `arity` variables
+func GnarkEvalConstraints(gate circuit.Gate, arity int) (int, error) {
+	definition := gnarkEvalCircuit{Inputs: make([]frontend.Variable, arity), gate: gate}
+	r1cs, err := frontend.Compile(ecc.BN254, backend.GROTH16, &definition)
+	if err != nil {
+		return 0, err
+	}
+	// Minus the check of the output
+	return r1cs.GetNbConstraints() - 1, nil
+}
+
+// sumcheckConstraints returns the number of constraints of the snark verifier of a sumcheck
+// with `nClaims` claims and `nRounds` rounds whose polynomials have `nCoeffs` coefficients
+func sumcheckConstraints(nClaims, nRounds, nCoeffs int) int {
+	// Hash and recombination of the claims
+	res := nClaims*mimcHashConstraints + nClaims - 1
+	// Each round checks p(0) + p(1), hashes and evaluates p
+	res += nRounds * (1 + nCoeffs*mimcHashConstraints + nCoeffs - 1)
+	return res
+}
+
+// multilinEvalConstraints returns the number of constraints to evaluate a multilinear
+// polynomial on `bN` variables given by its table
+func multilinEvalConstraints(bN int) int {
+	return 1<<bN - 1
+}
+
+// fixedEvalConstraints returns the number of constraints to evaluate a multilinear polynomial
+// on `bN` variables whose table is constant : the first folding is a multiplication by a constant.
+func fixedEvalConstraints(bN int) int {
+	if bN == 0 {
+		return 0
+	}
+	return 1<<(bN-1) - 1
+}
+
+// eqTableConstraints returns the number of constraints to compute the table of eq
+// on `bN` variables. They are saved on the first variable if the multiplier is constant.
+func eqTableConstraints(bN int, constantMultiplier bool) int {
+	if bN == 0 {
+		return 0
+	}
+	if constantMultiplier {
+		return 1<<bN - 2
+	}
+	return 1<<bN - 1
+}
+
+// eqEvalConstraints returns the number of constraints to evaluate `nClaims` recombined
+// eq polynomials on `bN` variables, and whether the result is constant
+func eqEvalConstraints(bN, nClaims int) (res int, isConstant bool) {
+	if bN == 0 {
+		// All the evaluations are one, so the first step of the recombination is free
+		return common.Max(nClaims-2, 0), nClaims == 1
+	}
+	return nClaims*(2*bN-1) + nClaims - 1, false
+}
+
+// wiringPredicateConstraints returns the number of constraints to evaluate the predicate of
+// a wiring, and whether the result is constant
+func wiringPredicateConstraints(w circuit.Wiring, bN int, inputBits []int, nClaims int) (res int, isConstant bool) {
+	// Recombined eq tables of the qPrimes, the multiplier is updated for each extra claim
+	res = eqTableConstraints(bN, true) + (nClaims-1)*(eqTableConstraints(bN, false)+1)
+	for _, b := range inputBits {
+		res += eqTableConstraints(b, true)
+	}
+
+	// Multiplications by a constant are free
+	isConstant = true
+	for range w {
+		termIsConstant := bN == 0 && nClaims == 1
+		for _, b := range inputBits {
+			if !termIsConstant && b > 0 {
+				res++
+			}
+			termIsConstant = termIsConstant && b == 0
+		}
+		isConstant = isConstant && termIsConstant
+	}
+
+	return res, isConstant
+}
+
+func sum(xs ...int) int {
+	res := 0
+	for _, x := range xs {
+		res += x
+	}
+	return res
+}
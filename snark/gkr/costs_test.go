@@ -0,0 +1,54 @@
+package gkr
+
+import (
+	"testing"
+
+	"github.com/consensys/gkr-mimc/circuit"
+	"github.com/consensys/gkr-mimc/circuit/gates"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGnarkEvalConstraints(t *testing.T) {
+	testCases := []struct {
+		gate     circuit.Gate
+		arity    int
+		expected int
+	}{
+		{gate: gates.AddGate{}, arity: 2, expected: 0},
+		{gate: gates.MulGate{}, arity: 2, expected: 1},
+		// (x + y + c)^7 : square, cube, sixth power and seventh power
+		{gate: gates.NewCipherGate(fr.NewElement(1)), arity: 2, expected: 4},
+		// The selection costs a single constraint
+		{gate: gates.SelectGate{}, arity: 3, expected: 1},
+	}
+
+	for _, tc := range testCases {
+		actual, err := GnarkEvalConstraints(tc.gate, tc.arity)
+		assert.NoError(t, err)
+		assert.Equal(t, tc.expected, actual, tc.gate.ID())
+	}
+}
+
+func TestCosts(t *testing.T) {
+	b := circuit.NewBuilder()
+	x, y := b.Input(), b.Input()
+	b.Output(b.Apply(gates.MulGate{}, x, y))
+	c, err := b.Build()
+	assert.NoError(t, err)
+
+	costs, err := CircuitCosts(c, 4)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, costs.NbSumchecks)
+	// 4 rounds of degree 3, and the qPrimes of the output and the inputs and their claims
+	assert.Equal(t, 4*4+2*4+2+4, costs.ProofSize)
+
+	// The costs grow with the number of instances
+	larger, err := CircuitCosts(c, 5)
+	assert.NoError(t, err)
+	assert.Greater(t, larger.VerifierConstraints, costs.VerifierConstraints)
+
+	// The sizes of the inputs must be powers of two
+	_, err = CircuitCosts(c, -1)
+	assert.Error(t, err)
+}
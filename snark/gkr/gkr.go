@@ -5,6 +5,7 @@ import (
 	"sort"
 
 	"github.com/consensys/gkr-mimc/circuit"
+	"github.com/consensys/gkr-mimc/common"
 	"github.com/consensys/gkr-mimc/gkr"
 	poly "github.com/consensys/gkr-mimc/snark/polynomial"
 	"github.com/consensys/gkr-mimc/snark/sumcheck"
@@ -26,10 +27,22 @@ func AllocateProof(bN int, c circuit.Circuit) (proof Proof) {
 	proof.Claims = make([][]frontend.Variable, len(c))
 	proof.QPrimes = make([][][]frontend.Variable, len(c))
 
+	// Number of variables of each layer
+	bits, err := c.Bits(bN)
+	if err != nil {
+		panic(err)
+	}
+
 	for layer := range c {
 		// When the Gate is nil, then it's an input layer
-		if c[layer].Gate != nil {
-			proof.SumcheckProofs[layer] = sumcheck.AllocateProof(bN, c[layer].Gate)
+		if c[layer].IsWired() {
+			inputBits := make([]int, len(c[layer].In))
+			for k, inpL := range c[layer].In {
+				inputBits[k] = bits[inpL]
+			}
+			proof.SumcheckProofs[layer] = sumcheck.AllocateWiredProof(inputBits...)
+		} else if c[layer].Gate != nil {
+			proof.SumcheckProofs[layer] = sumcheck.AllocateProof(bits[layer], c[layer].Gate)
 		}
 
 		// We might also allocate qPrime and the claim for the last layer
@@ -39,14 +52,16 @@ func AllocateProof(bN int, c circuit.Circuit) (proof Proof) {
 		proof.QPrimes[layer] = make([][]frontend.Variable, len(c[layer].Out))
 
 		for j := range proof.QPrimes[layer] {
-			proof.QPrimes[layer][j] = make([]frontend.Variable, bN)
+			proof.QPrimes[layer][j] = make([]frontend.Variable, bits[layer])
 		}
 	}
 
-	// Special case : output layers are , they have no outputs
+	// Special case : output and assertion layers have no outputs
 	// But they need one qPrime and no claims
-	proof.Claims[len(proof.Claims)-1] = []frontend.Variable{}
-	proof.QPrimes[len(proof.Claims)-1] = [][]frontend.Variable{make([]frontend.Variable, bN)}
+	for _, o := range c.FinalLayers() {
+		proof.Claims[o] = []frontend.Variable{}
+		proof.QPrimes[o] = [][]frontend.Variable{make([]frontend.Variable, bits[o])}
+	}
 
 	return proof
 }
@@ -83,28 +98,56 @@ func (p *Proof) Assign(proof gkr.Proof) {
 	}
 }
 
-// AssertValid runs the GKR verifier
+// AssertValid runs the GKR verifier. `outputs` contains the values of the output layers,
+// in the order of `c.OutputLayers()`. The claims of the assertion layers are zero.
 func (proof *Proof) AssertValid(
 	cs frontend.API,
 	c circuit.Circuit,
 	qPrime []frontend.Variable,
 	inputs []poly.MultiLin,
-	outputs poly.MultiLin,
+	outputs []poly.MultiLin,
 ) {
 
 	nLayers := len(c)
+	outputLayers := c.OutputLayers()
 
-	for k := range qPrime {
-		cs.AssertIsEqual(proof.QPrimes[nLayers-1][0][k], qPrime[k])
+	if len(outputs) != len(outputLayers) {
+		panic(fmt.Sprintf("expected %v outputs but got %v", len(outputLayers), len(outputs)))
 	}
 
-	// keep the old vector of claims in a variable, that we can put back in the proof
+	// keep the old vectors of claims in a variable, that we can put back in the proof
 	// the goal here, is to not modify the proof when calling `Define`
-	oldClaim := proof.Claims[nLayers-1]
-	// this re-allocates
-	proof.Claims[nLayers-1] = append(proof.Claims[nLayers-1], outputs.Eval(cs, qPrime))
+	oldClaims := make([][]frontend.Variable, len(outputLayers))
+
+	for i, o := range outputLayers {
+		// Smaller output layers are evaluated on a prefix of qPrime
+		outQPrime := qPrime[:len(proof.QPrimes[o][0])]
+		for k := range outQPrime {
+			cs.AssertIsEqual(proof.QPrimes[o][0][k], outQPrime[k])
+		}
+
+		oldClaims[i] = proof.Claims[o]
+		// this re-allocates
+		proof.Claims[o] = append(proof.Claims[o], outputs[i].Eval(cs, outQPrime))
+	}
+
+	assertionLayers := c.AssertionLayers()
+	oldAssertionClaims := make([][]frontend.Variable, len(assertionLayers))
+	for i, o := range assertionLayers {
+		for k := range proof.QPrimes[o][0] {
+			cs.AssertIsEqual(proof.QPrimes[o][0][k], qPrime[k])
+		}
+
+		oldAssertionClaims[i] = proof.Claims[o]
+		proof.Claims[o] = append(proof.Claims[o], frontend.Variable(0))
+	}
 
 	for layer := nLayers - 1; layer >= 0; layer-- {
+		if c.IsFixedLayer(layer) {
+			proof.testFixed(cs, c, layer)
+			continue
+		}
+
 		if len(c[layer].In) < 1 {
 			// It's an input layer
 			// No, more sumcheck to verify
@@ -119,18 +162,25 @@ func (proof *Proof) AssertValid(
 		proof.testInitialRound(cs, inputs, layer)
 	}
 
-	// re-erase the claim. we added midway to revert the change and keep the proof invariant
-	proof.Claims[nLayers-1] = oldClaim
+	// re-erase the claims. we added midway to revert the change and keep the proof invariant
+	for i, o := range outputLayers {
+		proof.Claims[o] = oldClaims[i]
+	}
+	for i, o := range assertionLayers {
+		proof.Claims[o] = oldAssertionClaims[i]
+	}
 
 }
 
 func (proof Proof) testSumcheck(cs frontend.API, c circuit.Circuit, layer int) {
 	// First thing, test the sumcheck
-	nextQprime, nextClaim, recombChal := proof.SumcheckProofs[layer].AssertValid(cs, proof.Claims[layer])
+	challenges, nextClaim, recombChal := proof.SumcheckProofs[layer].AssertValid(cs, proof.Claims[layer])
 	// 2 is because in practice, a gate cannot have more than two inputs with our designs
 	subClaims := make([]frontend.Variable, 0, 2)
+	inputQPrimes := make([][]frontend.Variable, len(c[layer].In))
+	offset := 0
 
-	for _, inpL := range c[layer].In {
+	for k, inpL := range c[layer].In {
 		// Seach the position of `l` as an output of layer `inpL`
 		// It works because `c[inpL].Out` is guaranteed to be sorted.
 		readAt := sort.SearchInts(c[inpL].Out, layer)
@@ -141,8 +191,15 @@ func (proof Proof) testSumcheck(cs frontend.API, c circuit.Circuit, layer int) {
 			panic(fmt.Sprintf("circuit misformatted, In and Out are inconsistent between layers %v and %v", layer, inpL))
 		}
 
-		for k := range nextQprime {
-			cs.AssertIsEqual(proof.QPrimes[inpL][readAt][k], nextQprime[k])
+		// For wired layers, each input is evaluated on its own chunk of the challenges
+		inputQPrimes[k] = challenges
+		if c[layer].IsWired() {
+			inputQPrimes[k] = challenges[offset : offset+len(proof.QPrimes[inpL][readAt])]
+			offset += len(proof.QPrimes[inpL][readAt])
+		}
+
+		for i := range inputQPrimes[k] {
+			cs.AssertIsEqual(proof.QPrimes[inpL][readAt][i], inputQPrimes[k][i])
 		}
 
 		subClaims = append(subClaims, proof.Claims[inpL][readAt])
@@ -151,19 +208,79 @@ func (proof Proof) testSumcheck(cs frontend.API, c circuit.Circuit, layer int) {
 	// Run the gate to compute the expected claim
 	expectedClaim := c[layer].Gate.GnarkEval(cs, subClaims...)
 
-	// Evaluation of eq to be used for testing the consistency with the challenges
-	// Recombines the eq evaluations into a single challenge
-	tmpEvals := make(poly.Univariate, len(proof.QPrimes[layer]))
-	for i := range proof.QPrimes[layer] {
-		tmpEvals[i] = poly.EqEval(cs, proof.QPrimes[layer][i], nextQprime)
+	var eqEval frontend.Variable
+	if c[layer].IsWired() {
+		// The wiring predicate plays the role of eq
+		eqEval = evalWiringPredicate(cs, c[layer].Wiring, proof.QPrimes[layer], recombChal, inputQPrimes...)
+	} else {
+		// Evaluation of eq to be used for testing the consistency with the challenges
+		// Recombines the eq evaluations into a single challenge
+		tmpEvals := make(poly.Univariate, len(proof.QPrimes[layer]))
+		for i := range proof.QPrimes[layer] {
+			tmpEvals[i] = poly.EqEval(cs, proof.QPrimes[layer][i], challenges)
+		}
+		eqEval = tmpEvals.Eval(cs, recombChal)
 	}
-	eqEval := tmpEvals.Eval(cs, recombChal)
 	expectedClaim = cs.Mul(expectedClaim, eqEval)
 	cs.AssertIsEqual(expectedClaim, nextClaim)
 }
 
+// testFixed evaluates the values of a fixed layer on each of its qPrimes and checks the claims.
+// The values are constants of the circuit, only the last coordinates of the qPrimes matter.
+func (proof Proof) testFixed(cs frontend.API, c circuit.Circuit, layer int) {
+	table := make(poly.MultiLin, len(c[layer].Fixed))
+	for i := range table {
+		table[i] = c[layer].Fixed[i]
+	}
+	bits := common.Log2Ceil(len(table))
+
+	for j, qPrime := range proof.QPrimes[layer] {
+		actual := table.Eval(cs, qPrime[len(qPrime)-bits:])
+		cs.AssertIsEqual(actual, proof.Claims[layer][j])
+	}
+}
+
 func (proof Proof) testInitialRound(cs frontend.API, inps []poly.MultiLin, layer int) error {
 	actual := inps[layer].Eval(cs, proof.QPrimes[layer][0])
 	cs.AssertIsEqual(actual, proof.Claims[layer][0])
 	return nil
 }
+
+// evalWiringPredicate is the gnark equivalent of `circuit.Wiring.EvalPredicate`
+func evalWiringPredicate(
+	cs frontend.API,
+	w circuit.Wiring,
+	qPrimes [][]frontend.Variable,
+	recombChal frontend.Variable,
+	points ...[]frontend.Variable,
+) frontend.Variable {
+
+	// Recombines the eq tables of the qPrimes
+	eqQ := poly.EqTable(cs, qPrimes[0], 1)
+	multiplier := recombChal
+	for j := 1; j < len(qPrimes); j++ {
+		tmp := poly.EqTable(cs, qPrimes[j], multiplier)
+		for z := range eqQ {
+			eqQ[z] = cs.Add(eqQ[z], tmp[z])
+		}
+		multiplier = cs.Mul(multiplier, recombChal)
+	}
+
+	eqPoints := make([][]frontend.Variable, len(points))
+	for k := range points {
+		eqPoints[k] = poly.EqTable(cs, points[k], 1)
+	}
+
+	terms := make([]frontend.Variable, len(w))
+	for i, e := range w {
+		terms[i] = eqQ[e.Out]
+		for k := range eqPoints {
+			terms[i] = cs.Mul(terms[i], eqPoints[k][e.In[k]])
+		}
+	}
+
+	if len(terms) == 1 {
+		return terms[0]
+	}
+	return cs.Add(terms[0], terms[1], terms[2:]...)
+}
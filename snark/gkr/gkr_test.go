@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/consensys/gkr-mimc/circuit"
+	"github.com/consensys/gkr-mimc/circuit/gates"
 	"github.com/consensys/gkr-mimc/common"
 	"github.com/consensys/gkr-mimc/examples"
 	"github.com/consensys/gkr-mimc/gkr"
@@ -60,7 +61,7 @@ func (c *GKRMimcTestCircuit) Assign(
 }
 
 func (c *GKRMimcTestCircuit) Define(cs frontend.API) error {
-	c.Proof.AssertValid(cs, c.Circuit, c.QInitialprime, c.Inputs, c.Output)
+	c.Proof.AssertValid(cs, c.Circuit, c.QInitialprime, c.Inputs, []poly.MultiLin{c.Output})
 	return nil
 }
 
@@ -88,7 +89,7 @@ func TestGkrCircuit(t *testing.T) {
 		outputs := a[93].DeepCopy()
 		gkrProof := gkr.Prove(c, a, qPrime)
 
-		err = gkr.Verify(c, gkrProof, inputs, outputs, qPrime)
+		err = gkr.Verify(c, gkrProof, inputs, []polyFr.MultiLin{outputs}, qPrime)
 		if err != nil {
 			panic(err)
 		}
@@ -107,6 +108,230 @@ func TestGkrCircuit(t *testing.T) {
 
 }
 
+// wiredTestCircuit verifies the GKR proof of `wiredCircuit`
+type wiredTestCircuit struct {
+	Circuit circuit.Circuit `gnark:"-"`
+	Proof   Proof
+	QPrime  []frontend.Variable
+	Inputs  []poly.MultiLin
+	Outputs []poly.MultiLin
+}
+
+// wiredCircuit returns a circuit mixing wired and data-parallel layers
+func wiredCircuit(bn int) circuit.Circuit {
+	n := 1 << bn
+	rotate := circuit.Wiring{}
+	reverse := circuit.Wiring{}
+	for z := 0; z < n; z++ {
+		rotate = append(rotate, circuit.WiringEntry{Out: z, In: []int{z, (z + 1) % n}})
+		reverse = append(reverse, circuit.WiringEntry{Out: z, In: []int{n - 1 - z}})
+	}
+
+	b := circuit.NewBuilder()
+	x, y := b.Input(), b.Input()
+	prod := b.ApplyWired(gates.MulGate{}, rotate, x, y)
+	rev := b.ApplyWired(gates.IdentityGate{}, reverse, prod)
+	b.Output(b.Apply(gates.AddGate{}, rev, prod))
+
+	c, err := b.Build()
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// sumTreeCircuit returns a circuit summing its inputs level by level, with layers of decreasing sizes
+func sumTreeCircuit(bn int) circuit.Circuit {
+	b := circuit.NewBuilder()
+	level := b.Input()
+	for size := 1 << (bn - 1); size >= 1; size /= 2 {
+		pairs := circuit.Wiring{}
+		for z := 0; z < size; z++ {
+			pairs = append(pairs, circuit.WiringEntry{Out: z, In: []int{2 * z, 2*z + 1}})
+		}
+		level = b.ApplySized(gates.AddGate{}, pairs, size, level, level)
+	}
+	b.Output(level)
+
+	c, err := b.Build()
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+func allocateWiredTestCircuit(bn int, c circuit.Circuit) wiredTestCircuit {
+	bits, err := c.Bits(bn)
+	if err != nil {
+		panic(err)
+	}
+
+	res := wiredTestCircuit{
+		Circuit: c,
+		Proof:   AllocateProof(bn, c),
+		QPrime:  make([]frontend.Variable, c.OutputBits(bits)),
+		Inputs:  make([]poly.MultiLin, c.InputArity()),
+	}
+	for i := range res.Inputs {
+		res.Inputs[i] = poly.AllocateMultilinear(bn)
+	}
+	for _, o := range c.OutputLayers() {
+		res.Outputs = append(res.Outputs, poly.AllocateMultilinear(bits[o]))
+	}
+	return res
+}
+
+func (c *wiredTestCircuit) Define(cs frontend.API) error {
+	c.Proof.AssertValid(cs, c.Circuit, c.QPrime, c.Inputs, c.Outputs)
+	return nil
+}
+
+func testGkrCircuitWired(t *testing.T, bn int, c circuit.Circuit) {
+	inputs := make([]polyFr.MultiLin, c.InputArity())
+	for i := range inputs {
+		inputs[i] = common.RandomFrArray(1 << bn)
+	}
+	if err := solveGkrCircuit(bn, c, inputs, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := solveGkrCircuit(bn, c, inputs, true); err == nil {
+		t.Fatal("the verifier accepted a wrong output")
+	}
+}
+
+// solveGkrCircuit proves the circuit on `inputs` and runs the gnark verifier of the proof.
+// The last layer of the circuit must be its only output, it is corrupted if `wrongOutput` is set.
+func solveGkrCircuit(bn int, c circuit.Circuit, inputs []polyFr.MultiLin, wrongOutput bool) error {
+	bits, err := c.Bits(bn)
+	if err != nil {
+		return err
+	}
+	qPrime := common.RandomFrArray(c.OutputBits(bits))
+	a := c.Assign(inputs...)
+	outputs := a[len(c)-1].DeepCopy()
+	proof := gkr.Prove(c, a, qPrime)
+
+	if wrongOutput {
+		outputs[0].SetOne()
+	}
+
+	witness := allocateWiredTestCircuit(bn, c)
+	witness.Proof.Assign(proof)
+	for i := range qPrime {
+		witness.QPrime[i] = qPrime[i]
+	}
+	for i := range inputs {
+		witness.Inputs[i].Assign(inputs[i])
+	}
+	witness.Outputs[0].Assign(outputs)
+
+	definition := allocateWiredTestCircuit(bn, c)
+	return test.IsSolved(&definition, &witness, ecc.BN254, backend.GROTH16)
+}
+
+func TestGkrCircuitWired(t *testing.T) {
+	testGkrCircuitWired(t, 2, wiredCircuit(2))
+}
+
+func TestGkrCircuitSized(t *testing.T) {
+	testGkrCircuitWired(t, 3, sumTreeCircuit(3))
+}
+
+// fixedCircuit multiplies the input with a selector, adds the selector of the next instance and a constant
+func fixedCircuit(bn int) circuit.Circuit {
+	n := 1 << bn
+	rotate := circuit.Wiring{}
+	for z := 0; z < n; z++ {
+		rotate = append(rotate, circuit.WiringEntry{Out: z, In: []int{z, (z + 1) % n}})
+	}
+
+	b := circuit.NewBuilder()
+	x := b.Input()
+	sel := b.Fixed([]fr.Element{fr.NewElement(1), fr.NewElement(2)})
+	prod := b.Apply(gates.MulGate{}, x, sel)
+	shifted := b.ApplyWired(gates.AddGate{}, rotate, prod, sel)
+	b.Output(b.Apply(gates.AddGate{}, shifted, b.Constant(fr.NewElement(7))))
+
+	c, err := b.Build()
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+func TestGkrCircuitFixed(t *testing.T) {
+	testGkrCircuitWired(t, 2, fixedCircuit(2))
+}
+
+// assertionCircuit checks that its first input is boolean and outputs the product of its inputs
+func assertionCircuit() circuit.Circuit {
+	b := circuit.NewBuilder()
+	x, y := b.Input(), b.Input()
+	isBool := gates.FromExpression(gates.Prod(gates.Input(0), gates.Sub(gates.Input(0), gates.Constant(fr.NewElement(1)))))
+	b.AssertZero(b.Apply(isBool, x))
+	b.Output(b.Apply(gates.MulGate{}, x, y))
+
+	c, err := b.Build()
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+func TestGkrCircuitAssertion(t *testing.T) {
+	bn := 2
+	c := assertionCircuit()
+
+	xs := make([]fr.Element, 1<<bn)
+	for z := range xs {
+		xs[z].SetUint64(uint64(z % 2))
+	}
+	inputs := []polyFr.MultiLin{xs, common.RandomFrArray(1 << bn)}
+	if err := solveGkrCircuit(bn, c, inputs, false); err != nil {
+		t.Fatal(err)
+	}
+
+	xs[1].SetUint64(2)
+	if err := solveGkrCircuit(bn, c, inputs, false); err == nil {
+		t.Fatal("the verifier accepted a broken assertion")
+	}
+}
+
+func TestEstimatedConstraints(t *testing.T) {
+	testCases := []struct {
+		name string
+		bn   int
+		c    circuit.Circuit
+	}{
+		{name: "mimc", bn: 1, c: examples.MimcCircuit()},
+		{name: "mimc", bn: 2, c: examples.MimcCircuit()},
+		{name: "wired", bn: 2, c: wiredCircuit(2)},
+		{name: "sized", bn: 3, c: sumTreeCircuit(3)},
+		{name: "chain", bn: 2, c: examples.MimcChainCircuit(2)},
+		{name: "fixed", bn: 1, c: fixedCircuit(1)},
+		{name: "fixed", bn: 3, c: fixedCircuit(3)},
+		{name: "assertion", bn: 0, c: assertionCircuit()},
+		{name: "assertion", bn: 2, c: assertionCircuit()},
+	}
+
+	for _, tc := range testCases {
+		costs, err := CircuitCosts(tc.c, tc.bn)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		definition := allocateWiredTestCircuit(tc.bn, tc.c)
+		r1cs, err := frontend.Compile(ecc.BN254, backend.GROTH16, &definition)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if costs.VerifierConstraints != r1cs.GetNbConstraints() {
+			t.Errorf("%v (bn = %v) : estimated %v constraints but got %v", tc.name, tc.bn, costs.VerifierConstraints, r1cs.GetNbConstraints())
+		}
+	}
+}
+
 func BenchmarkMimcCircuit(b *testing.B) {
 	// This will run the benchmark until, a SIGKILL happens
 	// Or there is enough memory to run 32M hashes (=> impossible)
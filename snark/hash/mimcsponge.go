@@ -0,0 +1,40 @@
+package hash
+
+import (
+	"github.com/consensys/gkr-mimc/hash"
+
+	"github.com/AlexandreBelling/gnark/frontend"
+)
+
+// MimcSpongePermutation is the MiMC-Feistel permutation of circomlib, see `hash.MimcSpongePermutation`
+func MimcSpongePermutation(cs frontend.API, xL, xR, k frontend.Variable) (frontend.Variable, frontend.Variable) {
+	for i := 0; i < hash.MimcSpongeRounds; i++ {
+		t := cs.Add(xL, k, hash.MimcSpongeConstants[i])
+		// Raise to the power 5
+		t2 := cs.Mul(t, t)   // ^2
+		t4 := cs.Mul(t2, t2) // ^4
+		t = cs.Mul(t4, t)    // ^5
+		if i < hash.MimcSpongeRounds-1 {
+			xL, xR = cs.Add(xR, t), xL
+		} else {
+			xR = cs.Add(xR, t)
+		}
+	}
+	return xL, xR
+}
+
+// MimcSpongeMultiHash is circomlib's MiMCSponge, see `hash.MimcSpongeMultiHash`
+func MimcSpongeMultiHash(cs frontend.API, inputs []frontend.Variable, key frontend.Variable, nbOutputs int) []frontend.Variable {
+	r, c := frontend.Variable(0), frontend.Variable(0)
+	for _, x := range inputs {
+		r = cs.Add(r, x)
+		r, c = MimcSpongePermutation(cs, r, c, key)
+	}
+
+	outputs := []frontend.Variable{r}
+	for i := 1; i < nbOutputs; i++ {
+		r, c = MimcSpongePermutation(cs, r, c, key)
+		outputs = append(outputs, r)
+	}
+	return outputs
+}
@@ -0,0 +1,51 @@
+package hash
+
+import (
+	"testing"
+
+	"github.com/consensys/gkr-mimc/common"
+	"github.com/consensys/gkr-mimc/hash"
+
+	"github.com/AlexandreBelling/gnark/backend"
+	"github.com/AlexandreBelling/gnark/frontend"
+	"github.com/AlexandreBelling/gnark/test"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+)
+
+type TestMimcSpongeCircuit struct {
+	X   []frontend.Variable
+	Key frontend.Variable
+	Y   []frontend.Variable
+}
+
+func (c *TestMimcSpongeCircuit) Define(cs frontend.API) error {
+	y := MimcSpongeMultiHash(cs, c.X, c.Key, len(c.Y))
+	for k := range y {
+		cs.AssertIsEqual(c.Y[k], y[k])
+	}
+	return nil
+}
+
+func TestMimcSponge(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	x := common.RandomFrArray(3)
+	key := common.RandomFrArray(1)[0]
+	y := hash.MimcSpongeMultiHash(x, key, 2)
+
+	c := TestMimcSpongeCircuit{X: make([]frontend.Variable, 3), Y: make([]frontend.Variable, 2)}
+	witness := TestMimcSpongeCircuit{X: make([]frontend.Variable, 3), Key: key, Y: make([]frontend.Variable, 2)}
+	for k := range x {
+		witness.X[k] = x[k]
+	}
+	for k := range y {
+		witness.Y[k] = y[k]
+	}
+	assert.SolvingSucceeded(&c, &witness, test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+
+	var one fr.Element
+	one.SetOne()
+	witness.Y[1] = *one.Add(&one, &y[1])
+	assert.SolvingFailed(&c, &witness, test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+}
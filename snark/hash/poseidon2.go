@@ -0,0 +1,70 @@
+package hash
+
+import (
+	"github.com/consensys/gkr-mimc/hash"
+
+	"github.com/AlexandreBelling/gnark/frontend"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+)
+
+// Poseidon2Permutation returns the Poseidon2 permutation of the state, see `hash.Poseidon2Hasher.Permutation`.
+// The linear layers are free, only the S-boxes cost constraints.
+func Poseidon2Permutation(cs frontend.API, p *hash.Poseidon2Hasher, state []frontend.Variable) []frontend.Variable {
+	external := p.ExternalMatrix()
+	internal := p.InternalMatrix()
+	nRoundsF, nRoundsP := p.NbRounds()
+
+	state = matrixMul(cs, external, state)
+
+	for i, arks := range p.Arks() {
+		state = append([]frontend.Variable{}, state...)
+		for k := range arks {
+			state[k] = sBox5(cs, cs.Add(state[k], arks[k]))
+		}
+		if i < nRoundsF || i >= nRoundsF+nRoundsP {
+			state = matrixMul(cs, external, state)
+		} else {
+			state = matrixMul(cs, internal, state)
+		}
+	}
+
+	return state
+}
+
+// Poseidon2Hash hashes the message with the sponge of `hash.Poseidon2Hasher.Hash`
+func Poseidon2Hash(cs frontend.API, p *hash.Poseidon2Hasher, msg ...frontend.Variable) frontend.Variable {
+	state := make([]frontend.Variable, p.T())
+	state[0] = len(msg)
+	for k := 1; k < len(state); k++ {
+		state[k] = 0
+	}
+
+	rate := p.T() - 1
+	for i := 0; i < len(msg) || i == 0; i += rate {
+		for j := 0; j < rate && i+j < len(msg); j++ {
+			state[j+1] = cs.Add(state[j+1], msg[i+j])
+		}
+		state = Poseidon2Permutation(cs, p, state)
+	}
+
+	return state[1]
+}
+
+// sBox5 returns x^5
+func sBox5(cs frontend.API, x frontend.Variable) frontend.Variable {
+	x2 := cs.Mul(x, x)
+	x4 := cs.Mul(x2, x2)
+	return cs.Mul(x4, x)
+}
+
+// matrixMul returns mat * vec, the multiplications by constants are free
+func matrixMul(cs frontend.API, mat [][]fr.Element, vec []frontend.Variable) []frontend.Variable {
+	res := make([]frontend.Variable, len(mat))
+	for i := range mat {
+		res[i] = frontend.Variable(0)
+		for j := range vec {
+			res[i] = cs.Add(res[i], cs.Mul(vec[j], mat[i][j]))
+		}
+	}
+	return res
+}
@@ -0,0 +1,55 @@
+package hash
+
+import (
+	"testing"
+
+	"github.com/consensys/gkr-mimc/common"
+	"github.com/consensys/gkr-mimc/hash"
+
+	"github.com/AlexandreBelling/gnark/backend"
+	"github.com/AlexandreBelling/gnark/frontend"
+	"github.com/AlexandreBelling/gnark/test"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+)
+
+type TestPoseidon2Circuit struct {
+	State    []frontend.Variable
+	Permuted []frontend.Variable
+	Digest   frontend.Variable
+	hasher   *hash.Poseidon2Hasher
+}
+
+func (c *TestPoseidon2Circuit) Define(cs frontend.API) error {
+	permuted := Poseidon2Permutation(cs, c.hasher, c.State)
+	for k := range permuted {
+		cs.AssertIsEqual(c.Permuted[k], permuted[k])
+	}
+	cs.AssertIsEqual(c.Digest, Poseidon2Hash(cs, c.hasher, c.State...))
+	return nil
+}
+
+func TestPoseidon2(t *testing.T) {
+	for _, p := range []*hash.Poseidon2Hasher{&hash.Poseidon2T2, &hash.Poseidon2T3, &hash.Poseidon2T4, &hash.Poseidon2T8} {
+		// The compiled circuits are cached by the assertion helper, which cannot tell the widths apart
+		assert := test.NewAssert(t)
+
+		state := common.RandomFrArray(p.T())
+		digest := p.Hash(state)
+		permuted := append([]fr.Element{}, state...)
+		p.Permutation(permuted)
+
+		c := TestPoseidon2Circuit{State: make([]frontend.Variable, p.T()), Permuted: make([]frontend.Variable, p.T()), hasher: p}
+		witness := TestPoseidon2Circuit{State: make([]frontend.Variable, p.T()), Permuted: make([]frontend.Variable, p.T()), Digest: digest}
+		for k := range state {
+			witness.State[k] = state[k]
+			witness.Permuted[k] = permuted[k]
+		}
+		assert.SolvingSucceeded(&c, &witness, test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+
+		var one fr.Element
+		one.SetOne()
+		witness.Permuted[p.T()-1] = *one.Add(&one, &permuted[p.T()-1])
+		assert.SolvingFailed(&c, &witness, test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+	}
+}
@@ -23,3 +23,22 @@ func EqEval(cs frontend.API, qPrime, hPrime []frontend.Variable) frontend.Variab
 	}
 	return res
 }
+
+// EqTable returns the table of Eq(q', h) for all the points h of the hypercube,
+// in the same order as `poly.FoldedEqTable`, each value being multiplied by `multiplier`
+func EqTable(cs frontend.API, qPrime []frontend.Variable, multiplier frontend.Variable) []frontend.Variable {
+	n := len(qPrime)
+	res := make([]frontend.Variable, 1<<n)
+	res[0] = multiplier
+
+	for i, r := range qPrime {
+		for j := 0; j < (1 << i); j++ {
+			J := j << (n - i)
+			JNext := J + 1<<(n-1-i)
+			res[JNext] = cs.Mul(r, res[J])
+			res[J] = cs.Sub(res[J], res[JNext])
+		}
+	}
+
+	return res
+}
@@ -23,6 +23,20 @@ func AllocateProof(bN int, gate circuit.Gate) Proof {
 	return proof
 }
 
+// AllocateWiredProof allocates an empty sumcheck verifier for a layer with a wiring predicate
+// whose inputs have `inputBits` variables. It runs one round of degree 2 per variable. See `sumcheck.ProveWired`.
+func AllocateWiredProof(inputBits ...int) Proof {
+	nRounds := 0
+	for _, b := range inputBits {
+		nRounds += b
+	}
+	proof := make(Proof, nRounds)
+	for i := range proof {
+		proof[i] = polynomial.AllocateUnivariate(2)
+	}
+	return proof
+}
+
 // Assign values for the sumcheck verifier
 func (p Proof) Assign(proof sumcheck.Proof) {
 	if len(proof) != len(p) {
@@ -1,6 +1,7 @@
 package sumcheck
 
 import (
+	"github.com/consensys/gkr-mimc/circuit"
 	"github.com/consensys/gkr-mimc/common"
 	"github.com/consensys/gkr-mimc/poly"
 	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
@@ -58,6 +59,17 @@ func (inst *instance) getPartialPolyChunk(start, stop int) []fr.Element {
 	nInputs := len(inst.X)
 	mid := len(inst.Eq) / 2
 
+	// The gate may compute the round polynomial itself, see `circuit.SumcheckGate`
+	if gate, ok := inst.gate.(circuit.SumcheckGate); ok {
+		x0s := make([][]fr.Element, nInputs)
+		x1s := make([][]fr.Element, nInputs)
+		for k := range inst.X {
+			x0s[k] = inst.X[k][start:stop]
+			x1s[k] = inst.X[k][start+mid : stop+mid]
+		}
+		return gate.PartialEvals(inst.Eq[start:stop], inst.Eq[start+mid:stop+mid], x0s, x1s)
+	}
+
 	// Contains the output of the algo
 	evals := make([]fr.Element, nEvals)
 
@@ -66,14 +78,19 @@ func (inst *instance) getPartialPolyChunk(start, stop int) []fr.Element {
 	tmpEvals := poly.MakeSmall(evalSubChunkSize)
 	tmpEqs := poly.MakeSmall(evalSubChunkSize)
 	dEqs := poly.MakeSmall(evalSubChunkSize)
-	tmpXs := poly.MakeSmall(evalSubChunkSize * nInputs)
-	dXs := poly.MakeSmall(evalSubChunkSize * nInputs)
+	// One buffer per input, so that the number of inputs is not bounded by the size of the pool
+	tmpXs := make([]poly.MultiLin, nInputs)
+	dXs := make([]poly.MultiLin, nInputs)
+	for k := 0; k < nInputs; k++ {
+		tmpXs[k] = poly.MakeSmall(evalSubChunkSize)
+		dXs[k] = poly.MakeSmall(evalSubChunkSize)
+	}
 
 	defer poly.DumpSmall(tmpEvals)
 	defer poly.DumpSmall(tmpEqs)
 	defer poly.DumpSmall(dEqs)
-	defer poly.DumpSmall(tmpXs)
-	defer poly.DumpSmall(dXs)
+	defer poly.DumpSmall(tmpXs...)
+	defer poly.DumpSmall(dXs...)
 
 	// Set of pointers to tmpXs that can be passed directly
 	// to `gate.Evals`
@@ -89,7 +106,6 @@ func (inst *instance) getPartialPolyChunk(start, stop int) []fr.Element {
 		// Precomputations to save a few additions
 		subChunkStartPlusMid := subChunkStart + mid
 		subChunkEndPlusMid := subChunkEnd + mid
-		nInputsSubChunkLen := nInputs * subChunkLen
 
 		if subChunkLen < evalSubChunkSize {
 			// Can only happen at the last iteration
@@ -97,8 +113,10 @@ func (inst *instance) getPartialPolyChunk(start, stop int) []fr.Element {
 			tmpEvals = tmpEvals[:subChunkLen]
 			tmpEqs = tmpEqs[:subChunkLen]
 			dEqs = dEqs[:subChunkLen]
-			tmpXs = tmpXs[:subChunkLen*nInputs]
-			dXs = dXs[:subChunkLen*nInputs]
+			for k := 0; k < nInputs; k++ {
+				tmpXs[k] = tmpXs[k][:subChunkLen]
+				dXs[k] = dXs[k][:subChunkLen]
+			}
 		}
 
 		// Special case: evaluation at t = 0
@@ -158,20 +176,18 @@ func (inst *instance) getPartialPolyChunk(start, stop int) []fr.Element {
 		}
 
 		for k := range inst.X {
-			kOffset := k * subChunkLen
-
 			// Initializes the dXs as P(t=1, x) - P(t=0, x)
 			for x := 0; x < subChunkLen; x++ {
-				dXs[kOffset+x].Sub(&inst.X[k][subChunkStartPlusMid+x], &inst.X[k][subChunkStart+x])
+				dXs[k][x].Sub(&inst.X[k][subChunkStartPlusMid+x], &inst.X[k][subChunkStart+x])
 			}
 
 			// As for eq, we initialize each input table `X` with the value for t = 1
 			// (We get the next values for t by adding dXs)
-			copy(tmpXs[kOffset:kOffset+subChunkLen], inst.X[k][subChunkStartPlusMid:subChunkEndPlusMid])
+			copy(tmpXs[k], inst.X[k][subChunkStartPlusMid:subChunkEndPlusMid])
 
-			// Also, we redirect the evaluation buffer over each subslice of tmpXs
+			// Also, we redirect the evaluation buffer over each tmpXs
 			// So we can easily pass each of these values of to the `gates.EvalBatch` table
-			evaluationBuffer[k] = tmpXs[kOffset : kOffset+subChunkLen]
+			evaluationBuffer[k] = tmpXs[k]
 		}
 
 		for t := 2; t < nEvals; t++ {
@@ -183,11 +199,12 @@ func (inst *instance) getPartialPolyChunk(start, stop int) []fr.Element {
 				tmpEqs[x].Add(&tmpEqs[x], &dEqs[x])
 			}
 
-			// Update the value of tmpXs : as dXs and tmpXs have the same layout,
-			// no need to make a double loop on k : the index of the separate inputs
+			// Update the value of tmpXs
 			// We can do this, because P is multilinear so P(t+1,x) = P(t, x) + dX(x)
-			for kx := 0; kx < nInputsSubChunkLen; kx++ {
-				tmpXs[kx].Add(&tmpXs[kx], &dXs[kx])
+			for k := 0; k < nInputs; k++ {
+				for x := 0; x < subChunkLen; x++ {
+					tmpXs[k][x].Add(&tmpXs[k][x], &dXs[k][x])
+				}
 			}
 
 			// Recall that evaluationBuffer is a set of pointers to subslices of tmpXs
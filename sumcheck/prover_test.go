@@ -93,6 +93,29 @@ func TestWithCipherGate(t *testing.T) {
 	}
 }
 
+// genericGate hides the optional interfaces of the gate, so that the sumcheck evaluates it through `EvalBatch`
+type genericGate struct{ circuit.Gate }
+
+func TestSumcheckGateKernel(t *testing.T) {
+	for bn := 0; bn < 12; bn++ {
+		X, claims, qs, gate := InitializeCipherGateInstance(bn)
+		_, ok := gate.(circuit.SumcheckGate)
+		assert.True(t, ok, "the cipher gate computes its own round polynomials")
+
+		Xbis := make([]poly.MultiLin, len(X))
+		for k := range X {
+			Xbis[k] = poly.MakeLarge(len(X[k]))
+			copy(Xbis[k], X[k])
+		}
+
+		proof, challenges, fClm := Prove(X, qs, claims, gate)
+		proofBis, challengesBis, fClmBis := Prove(Xbis, qs, claims, genericGate{gate})
+		assert.Equal(t, proof, proofBis, "the proofs differ for bn = %v", bn)
+		assert.Equal(t, challenges, challengesBis)
+		assert.Equal(t, fClm, fClmBis)
+	}
+}
+
 func BenchmarkWithCipherGate(b *testing.B) {
 	bn := 22
 	b.Run(fmt.Sprintf("sumcheck-bn-%v", bn), func(b *testing.B) {
@@ -145,3 +168,19 @@ func BenchmarkPartialEvalWithCipher(b *testing.B) {
 		})
 	})
 }
+
+func BenchmarkPartialEvalKernel(b *testing.B) {
+	bn := 15
+	X, claims, qPrime, gate := InitializeCipherGateInstance(bn)
+	callback := make(chan []fr.Element, 8*runtime.NumCPU())
+
+	for name, g := range map[string]circuit.Gate{"kernel": gate, "generic": genericGate{gate}} {
+		inst := makeInstance(X, g)
+		makeEqTable(inst, claims, qPrime, callback)
+		b.Run(name, func(b *testing.B) {
+			for c_ := 0; c_ < b.N; c_++ {
+				dispatchPartialEvals(inst, callback)
+			}
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package sumcheck
+
+import (
+	"fmt"
+
+	"github.com/consensys/gkr-mimc/circuit"
+	"github.com/consensys/gkr-mimc/common"
+	"github.com/consensys/gkr-mimc/poly"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+)
+
+// ProveWired runs the sumcheck of a layer with a wiring predicate `W`, using the two-phase
+// algorithm of Libra. The sum "proven" is the following, for all `j`
+//
+//	\sum_{x, y} W(qPrime[j], x, y) * Gate(X[0][x], X[1][y])
+//
+// (or \sum_{x} W(qPrime[j], x) * Gate(X[0][x]) for layers with a single input).
+// The claims are recombined as in `Prove`.
+//
+// The first phase runs over `x`, the second one over `y`. Each of them takes as many
+// rounds of degree 2 as its input has variables : the inputs and the layer can have
+// different sizes. The gate must be multilinear, and the runtime is linear in
+// the size of the tables plus the number of wiring entries.
+//
+// It returns the prover messages of both phases, the concatenation of the challenges
+// of each phase (rx || ry) and the final claims : the evaluation of the wiring predicate
+// at (qPrime, rx, ry) followed by X[0](rx) and X[1](ry).
+func ProveWired(X []poly.MultiLin, qPrimes [][]fr.Element, claims []fr.Element, gate circuit.Gate, wiring circuit.Wiring) (proof Proof, challenges, finalClaims []fr.Element) {
+
+	n := 1 << len(qPrimes[0])
+
+	inSizes := make([]int, len(X))
+	for i, x := range X {
+		if len(x) == 0 || len(x)&(len(x)-1) != 0 {
+			panic(fmt.Sprintf("table %v has size %v which is not a power of two", i, len(x)))
+		}
+		inSizes[i] = len(x)
+	}
+	if err := wiring.CheckSizes(n, inSizes...); err != nil {
+		panic(err)
+	}
+
+	coeffs, err := circuit.MultilinearCoefficients(gate, len(X))
+	if err != nil {
+		panic(err)
+	}
+
+	// Coefficient of each wiring entry : the recombined eq(qPrime, Out)
+	inst := &instance{Eq: poly.MakeLarge(n)}
+	makeEqTable(inst, claims, qPrimes, nil)
+	entryCoeffs := make([]fr.Element, len(wiring))
+	for i, e := range wiring {
+		entryCoeffs[i] = inst.Eq[e.Out]
+	}
+	poly.DumpLarge(inst.Eq)
+
+	if len(X) == 1 {
+		// Single phase : \sum_x W(x) (c1 X(x) + c0)
+		w := make(poly.MultiLin, inSizes[0])
+		for i, e := range wiring {
+			w[e.In[0]].Add(&w[e.In[0]], &entryCoeffs[i])
+		}
+		wEval := w.DeepCopy()
+
+		q, s := scaledCopies(w, coeffs[1], coeffs[0])
+		proof, challenges = proveProductPlusLinear(X[0], q, s)
+		finalClaims = []fr.Element{wEval.Evaluate(challenges), X[0][0]}
+		return proof, challenges, finalClaims
+	}
+
+	// First phase : \sum_x X(x) A(x) + B(x)
+	// where A = c3 WR + c1 W1 and B = c2 WR + c0 W1
+	// with WR(x) = \sum_y W(x, y) Y(y) and W1(x) = \sum_y W(x, y)
+	wr := make(poly.MultiLin, inSizes[0])
+	w1 := make(poly.MultiLin, inSizes[0])
+	var tmp fr.Element
+	for i, e := range wiring {
+		tmp.Mul(&entryCoeffs[i], &X[1][e.In[1]])
+		wr[e.In[0]].Add(&wr[e.In[0]], &tmp)
+		w1[e.In[0]].Add(&w1[e.In[0]], &entryCoeffs[i])
+	}
+
+	a, b := make(poly.MultiLin, inSizes[0]), make(poly.MultiLin, inSizes[0])
+	for x := range a {
+		a[x].Mul(&coeffs[3], &wr[x])
+		tmp.Mul(&coeffs[1], &w1[x])
+		a[x].Add(&a[x], &tmp)
+
+		b[x].Mul(&coeffs[2], &wr[x])
+		tmp.Mul(&coeffs[0], &w1[x])
+		b[x].Add(&b[x], &tmp)
+	}
+
+	proofX, rx := proveProductPlusLinear(X[0], a, b)
+	xClaim := X[0][0]
+
+	// Second phase : \sum_y H(y) (c1' Y(y) + c0')
+	// where H(y) = W(rx, y), c1' = c3 X(rx) + c2 and c0' = c1 X(rx) + c0
+	eqRx := poly.FoldedEqTable(make(poly.MultiLin, inSizes[0]), rx)
+	h := make(poly.MultiLin, inSizes[1])
+	for i, e := range wiring {
+		tmp.Mul(&entryCoeffs[i], &eqRx[e.In[0]])
+		h[e.In[1]].Add(&h[e.In[1]], &tmp)
+	}
+	hEval := h.DeepCopy()
+
+	var c1, c0 fr.Element
+	c1.Mul(&coeffs[3], &xClaim)
+	c1.Add(&c1, &coeffs[2])
+	c0.Mul(&coeffs[1], &xClaim)
+	c0.Add(&c0, &coeffs[0])
+
+	q, s := scaledCopies(h, c1, c0)
+	proofY, ry := proveProductPlusLinear(X[1], q, s)
+
+	proof = append(proofX, proofY...)
+	challenges = append(rx, ry...)
+	finalClaims = []fr.Element{hEval.Evaluate(ry), xClaim, X[1][0]}
+
+	return proof, challenges, finalClaims
+}
+
+// scaledCopies returns (a * t, b * t)
+func scaledCopies(t poly.MultiLin, a, b fr.Element) (at, bt poly.MultiLin) {
+	at, bt = make(poly.MultiLin, len(t)), make(poly.MultiLin, len(t))
+	for i := range t {
+		at[i].Mul(&t[i], &a)
+		bt[i].Mul(&t[i], &b)
+	}
+	return at, bt
+}
+
+// proveProductPlusLinear runs the sumcheck for \sum_x P(x) Q(x) + S(x). All the tables
+// are folded in place : at the end, they contain their evaluation at the challenges.
+func proveProductPlusLinear(p, q, s poly.MultiLin) (proof Proof, challenges []fr.Element) {
+	bN := common.Log2Ceil(len(p))
+	proof = make(Proof, bN)
+	challenges = make([]fr.Element, bN)
+
+	var pt, qt, st, dp, dq, ds, tmp fr.Element
+
+	for k := 0; k < bN; k++ {
+		mid := len(p) / 2
+		// Evaluations of the round polynomial at 0, 1 and 2
+		evals := make([]fr.Element, 3)
+
+		for i := 0; i < mid; i++ {
+			dp.Sub(&p[i+mid], &p[i])
+			dq.Sub(&q[i+mid], &q[i])
+			ds.Sub(&s[i+mid], &s[i])
+
+			pt, qt, st = p[i], q[i], s[i]
+			for t := range evals {
+				if t > 0 {
+					pt.Add(&pt, &dp)
+					qt.Add(&qt, &dq)
+					st.Add(&st, &ds)
+				}
+				tmp.Mul(&pt, &qt)
+				tmp.Add(&tmp, &st)
+				evals[t].Add(&evals[t], &tmp)
+			}
+		}
+
+		proof[k] = poly.InterpolateOnRange(evals)
+		r := common.GetChallenge(proof[k])
+		challenges[k] = r
+
+		p.Fold(r)
+		q.Fold(r)
+		s.Fold(r)
+	}
+
+	return proof, challenges
+}
@@ -0,0 +1,95 @@
+package sumcheck
+
+import (
+	"testing"
+
+	"github.com/consensys/gkr-mimc/circuit"
+	"github.com/consensys/gkr-mimc/circuit/gates"
+	"github.com/consensys/gkr-mimc/common"
+	"github.com/consensys/gkr-mimc/poly"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/stretchr/testify/assert"
+)
+
+// randomWiring returns a wiring where each instance reads random instances of its inputs
+func randomWiring(bn, arity int) circuit.Wiring {
+	n := 1 << bn
+	res := make(circuit.Wiring, n)
+	for z := range res {
+		res[z] = circuit.WiringEntry{Out: z, In: make([]int, arity)}
+		for k := range res[z].In {
+			// Deterministic but non-trivial pattern
+			res[z].In[k] = (z*(2*k+3) + k + 1) % n
+		}
+	}
+	return res
+}
+
+func testWired(t *testing.T, bn, nClaims int, gate circuit.Gate, wiring circuit.Wiring, arity int) {
+	X := make([]poly.MultiLin, arity)
+	copies := make([]poly.MultiLin, arity)
+	inputs := make([][]fr.Element, arity)
+	for k := range X {
+		X[k] = common.RandomFrArray(1 << bn)
+		copies[k] = X[k].DeepCopy()
+		inputs[k] = X[k]
+	}
+
+	out := poly.MultiLin(wiring.Evaluate(gate, 1<<bn, inputs...))
+
+	qs := make([][]fr.Element, nClaims)
+	claims := make([]fr.Element, nClaims)
+	for j := range qs {
+		qs[j] = common.RandomFrArray(bn)
+		claims[j] = out.Evaluate(qs[j])
+	}
+
+	proof, challenges, finalClaims := ProveWired(X, qs, claims, gate, wiring)
+	challengesV, finalValue, recombChal, err := Verify(claims, proof)
+
+	assert.NoError(t, err)
+	assert.Equal(t, challenges, challengesV, "prover's and verifier challenges do not match")
+	assert.Len(t, challenges, arity*bn)
+
+	points := make([][]fr.Element, arity)
+	for k := range points {
+		points[k] = challenges[k*bn : (k+1)*bn]
+		assert.Equal(t, copies[k].Evaluate(points[k]), finalClaims[k+1], "wrong final claim for input %v", k)
+	}
+
+	predicate := wiring.EvalPredicate(qs, recombChal, points...)
+	assert.Equal(t, predicate, finalClaims[0], "wrong evaluation of the wiring predicate")
+
+	var expected fr.Element
+	ptrs := make([]*fr.Element, arity)
+	for k := range ptrs {
+		ptrs[k] = &finalClaims[k+1]
+	}
+	gate.Eval(&expected, ptrs...)
+	expected.Mul(&expected, &predicate)
+	assert.Equal(t, expected, finalValue, "inconsistency of the final values for the verifier")
+}
+
+func TestProveWired(t *testing.T) {
+	for bn := 1; bn < 8; bn++ {
+		for _, nClaims := range []int{1, 3} {
+			testWired(t, bn, nClaims, gates.MulGate{}, randomWiring(bn, 2), 2)
+			testWired(t, bn, nClaims, gates.AddGate{}, randomWiring(bn, 2), 2)
+			testWired(t, bn, nClaims, gates.IdentityGate{}, randomWiring(bn, 1), 1)
+			testWired(t, bn, nClaims, gates.NewLinearCombinationGate(
+				[]fr.Element{fr.NewElement(3), fr.NewElement(7)},
+				fr.NewElement(2),
+			), randomWiring(bn, 2), 2)
+		}
+	}
+}
+
+func TestProveWiredSparse(t *testing.T) {
+	// Sums the instances two by two : only half of the outputs are wired
+	bn := 4
+	wiring := circuit.Wiring{}
+	for z := 0; z < 1<<(bn-1); z++ {
+		wiring = append(wiring, circuit.WiringEntry{Out: z, In: []int{2 * z, 2*z + 1}})
+	}
+	testWired(t, bn, 2, gates.AddGate{}, wiring, 2)
+}